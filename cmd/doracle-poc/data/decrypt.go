@@ -0,0 +1,141 @@
+package data
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/envelope"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/storage"
+)
+
+var ErrContentHashMismatch = errors.New("content hash mismatch")
+
+// Decrypt downloads the data re-encrypted by oracles and decrypts it for data buyers.
+// The decrypted file is kept only if its hash matches the content hash committed by oracles.
+func Decrypt(args []string) error {
+	flags := flag.NewFlagSet("decrypt", flag.ExitOnError)
+	pStorage := flags.String("storage", "", "storage URI to download the encrypted file from")
+	pRef := flags.String("ref", "", "storage ref of the encrypted file")
+	pIn := flags.String("in", "", "encrypted file, instead of downloading it from the storage")
+	pOut := flags.String("out", "", "decrypted file")
+	pKeyFile := flags.String("key-file", "", "file containing the hex-encoded private key of the buyer")
+	pContentHash := flags.String("content-hash", "", "hex-encoded content hash committed on chain")
+	flags.Parse(args)
+
+	if *pOut == "" {
+		return fmt.Errorf("-out must be specified")
+	}
+	if *pContentHash == "" {
+		return fmt.Errorf("-content-hash must be specified")
+	}
+	expectedHash, err := hex.DecodeString(*pContentHash)
+	if err != nil {
+		return fmt.Errorf("failed to decode content hash: %w", err)
+	}
+
+	privKey, err := privKeyFromFile(*pKeyFile)
+	if err != nil {
+		return err
+	}
+
+	in, err := openEncrypted(*pIn, *pStorage, *pRef)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := decryptFile(in, *pOut, privKey, expectedHash); err != nil {
+		return err
+	}
+	log.Infof("decrypted data is written to %v", *pOut)
+
+	return nil
+}
+
+func privKeyFromFile(path string) (*btcec.PrivateKey, error) {
+	if path == "" {
+		return nil, fmt.Errorf("-key-file must be specified")
+	}
+
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %v: %w", path, err)
+	}
+	keyBytes, err := hex.DecodeString(strings.TrimSpace(string(bz)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode private key: %w", err)
+	}
+	if len(keyBytes) != btcec.PrivKeyBytesLen {
+		return nil, fmt.Errorf("invalid private key length: %v", len(keyBytes))
+	}
+
+	return secp256k1.PrivKeyFromBytes(keyBytes), nil
+}
+
+func openEncrypted(inPath, storageURI, ref string) (io.ReadCloser, error) {
+	if inPath != "" {
+		f, err := os.Open(inPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %v: %w", inPath, err)
+		}
+		return f, nil
+	}
+
+	if storageURI == "" || ref == "" {
+		return nil, fmt.Errorf("either -in or both -storage and -ref must be specified")
+	}
+	s, err := storage.New(storageURI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init storage: %w", err)
+	}
+	rc, err := s.Get(context.Background(), ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %v: %w", ref, err)
+	}
+	return rc, nil
+}
+
+// decryptFile writes the plaintext into a temporary file first,
+// so that outPath never contains data which doesn't match the expected hash.
+// An existing file at outPath is never replaced.
+func decryptFile(in io.Reader, outPath string, privKey *btcec.PrivateKey, expectedHash []byte) error {
+	dr, env, err := envelope.NewStreamReader(in, privKey)
+	if err != nil {
+		return fmt.Errorf("failed to init decryption: %w", err)
+	}
+	log.Debugf("envelope: version:%v, algorithm:%v, keyEpoch:%v", env.Version, env.Algorithm, env.KeyEpoch)
+
+	out, err := ioutil.TempFile(filepath.Dir(outPath), filepath.Base(outPath)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create a temp file for %v: %w", outPath, err)
+	}
+	tmpPath := out.Name()
+	defer os.Remove(tmpPath)
+	defer out.Close()
+
+	if _, err := io.Copy(out, dr); err != nil {
+		return fmt.Errorf("failed to decrypt: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to close %v: %w", tmpPath, err)
+	}
+
+	manifest, _ := dr.Manifest()
+	if !bytes.Equal(manifest.Digest[:], expectedHash) {
+		return fmt.Errorf("%w: committed:%x, downloaded:%x", ErrContentHashMismatch, expectedHash, manifest.Digest)
+	}
+
+	return createFrom(tmpPath, outPath)
+}
@@ -0,0 +1,162 @@
+package data
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/btcsuite/btcd/btcec"
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/query"
+	"github.com/youngjoon-lee/doracle-poc/pkg/envelope"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/storage"
+)
+
+// Encrypt encrypts a file to the oracle public key for data sellers. It doesn't need to be run in the SGX.
+func Encrypt(args []string) error {
+	flags := flag.NewFlagSet("encrypt", flag.ExitOnError)
+	pTendermintRPC := flags.String("tm-rpc", "tcp://127.0.0.1:26657", "tendermint rpc addr")
+	pIn := flags.String("in", "", "file to be encrypted")
+	pOut := flags.String("out", "", "encrypted file (default: <in>.enc)")
+	pPubKey := flags.String("pubkey", "", "hex-encoded public key to encrypt to, instead of the oracle public key on chain")
+	pAssociatedData := flags.String("ad", "", "associated data to be bound to the ciphertext")
+	pStorage := flags.String("storage", "", "storage URI to upload the encrypted file to (optional)")
+	flags.Parse(args)
+
+	if *pIn == "" {
+		return fmt.Errorf("-in must be specified")
+	}
+	outPath := *pOut
+	if outPath == "" {
+		outPath = *pIn + ".enc"
+	}
+
+	pubKey, err := recipientPubKey(*pTendermintRPC, *pPubKey)
+	if err != nil {
+		return err
+	}
+
+	// the ciphertext is written to a temp file, and linked to outPath only if everything succeeds,
+	// so that a partial ciphertext is never left at outPath
+	tmpPath, ciphertextHash, err := encryptFile(*pIn, outPath, pubKey, []byte(*pAssociatedData))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	var ref string
+	if *pStorage != "" {
+		ref, err = upload(*pStorage, tmpPath)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := createFrom(tmpPath, outPath); err != nil {
+		return err
+	}
+	log.Infof("%v is encrypted to %v", *pIn, outPath)
+
+	// the hash of the plaintext is not published, since it reveals the data if the data is guessable
+	fmt.Printf("ciphertext hash: %v\n", hex.EncodeToString(ciphertextHash))
+	fmt.Printf("encrypted file: %v\n", outPath)
+	if ref != "" {
+		fmt.Printf("storage ref: %v\n", ref)
+	}
+
+	return nil
+}
+
+func recipientPubKey(tendermintRPCAddr, pubKeyHex string) (*btcec.PublicKey, error) {
+	if pubKeyHex != "" {
+		pubKeyBytes, err := hex.DecodeString(pubKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode pubkey: %w", err)
+		}
+		return secp256k1.PubKeyFromBytes(pubKeyBytes)
+	}
+
+	queryClient, err := query.NewClient(tendermintRPCAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init query client: %w", err)
+	}
+	return queryClient.OraclePubKey(context.Background())
+}
+
+// createFrom creates path with the content of tmpPath, without replacing an existing file at path.
+// A hard link is used, since it fails atomically if path exists.
+func createFrom(tmpPath, path string) error {
+	if err := os.Link(tmpPath, path); err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%v already exists", path)
+		}
+		return fmt.Errorf("failed to create %v: %w", path, err)
+	}
+	return nil
+}
+
+// encryptFile encrypts the file into a temp file next to outPath,
+// and returns the path of the temp file with the SHA-256 hash of the ciphertext.
+// The temp file is removed if the encryption fails.
+func encryptFile(inPath, outPath string, pubKey *btcec.PublicKey, associatedData []byte) (_ string, _ []byte, err error) {
+	in, err := os.Open(inPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open %v: %w", inPath, err)
+	}
+	defer in.Close()
+
+	out, err := ioutil.TempFile(filepath.Dir(outPath), filepath.Base(outPath)+".tmp-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create a temp file for %v: %w", outPath, err)
+	}
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(out.Name())
+		}
+	}()
+
+	hash := sha256.New()
+	ew, err := envelope.NewStreamWriter(io.MultiWriter(out, hash), pubKey, 0, associatedData)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to init encryption: %w", err)
+	}
+	if _, err := in.WriteTo(ew); err != nil {
+		return "", nil, fmt.Errorf("failed to encrypt %v: %w", inPath, err)
+	}
+	if err := ew.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to finish encryption: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return "", nil, fmt.Errorf("failed to close %v: %w", out.Name(), err)
+	}
+
+	return out.Name(), hash.Sum(nil), nil
+}
+
+func upload(storageURI, path string) (string, error) {
+	s, err := storage.New(storageURI)
+	if err != nil {
+		return "", fmt.Errorf("failed to init storage: %w", err)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open %v: %w", path, err)
+	}
+	defer f.Close()
+
+	ref, err := s.Put(context.Background(), f)
+	if err != nil {
+		return "", fmt.Errorf("failed to upload %v: %w", path, err)
+	}
+	return ref, nil
+}
@@ -0,0 +1,56 @@
+package keys
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/tx"
+	"github.com/youngjoon-lee/doracle-poc/pkg/operator"
+)
+
+// RemoteSigner serves the remote signer protocol with the operator key loaded with the given flags.
+// It is a stand-in of a hardware wallet signer, which can be run on another host or as another user.
+// It doesn't need to be run in the SGX unless the operator key is sealed.
+func RemoteSigner(args []string) error {
+	cfg := config.Default()
+	flags := flag.NewFlagSet("remote-signer", flag.ExitOnError)
+	pConfig := cfg.RegisterFlags(flags)
+	pListen := flags.String("listen", "unix:///tmp/doracle-signer.sock", "addr to listen (unix:///path/to/socket or tcp://host:port)")
+	if err := cfg.Load(flags, pConfig, args); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.Operator.Source == config.OperatorSourceRemote {
+		return fmt.Errorf("the remote signer cannot use another remote signer")
+	}
+
+	app.SetDHubConfig()
+
+	signer, err := operator.LoadSigner(cfg.Operator)
+	if err != nil {
+		return err
+	}
+
+	listener, err := tx.ListenSigner(*pListen)
+	if err != nil {
+		return fmt.Errorf("failed to listen %v: %w", *pListen, err)
+	}
+	defer listener.Close()
+
+	go func() {
+		if err := tx.NewRemoteSignerServer(signer, cfg.ChainID).Serve(listener); err != nil {
+			log.Infof("remote signer stopped: %v", err)
+		}
+	}()
+	log.Infof("remote signer for %v (chain %v) listening on %v", signer.Address().String(), cfg.ChainID, *pListen)
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	<-sigCh
+	return nil
+}
@@ -0,0 +1,34 @@
+package keys
+
+import (
+	"flag"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/operator"
+)
+
+// SealOperatorKey imports the operator key from the configured source once, and seals it in the enclave.
+// It must be run in the SGX.
+func SealOperatorKey(args []string) error {
+	cfg := config.Default()
+	flags := flag.NewFlagSet("keys seal", flag.ExitOnError)
+	pConfig := cfg.RegisterFlags(flags)
+	if err := cfg.Load(flags, pConfig, args); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	app.SetDHubConfig()
+
+	addr, err := operator.SealKey(cfg.Operator)
+	if err != nil {
+		return err
+	}
+	log.Infof("operator key sealed to %v", cfg.Operator.SealedFile)
+
+	fmt.Printf("operator address: %v\n", addr.String())
+	fmt.Printf("sealed file: %v\n", cfg.Operator.SealedFile)
+	return nil
+}
@@ -0,0 +1,47 @@
+package keys
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/operator"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+// Show prints the bech32 address of the operator key loaded with the given flags,
+// so that operators can check which account will be used before funding it.
+// It also prints the oracle public key if the sealed oracle key can be unsealed, which requires the SGX.
+func Show(args []string) error {
+	cfg := config.Default()
+	flags := flag.NewFlagSet("keys show", flag.ExitOnError)
+	pConfig := cfg.RegisterFlags(flags)
+	if err := cfg.Load(flags, pConfig, args); err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	app.SetDHubConfig()
+
+	signer, err := operator.LoadSigner(cfg.Operator)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("operator address: %v\n", signer.Address().String())
+	if operator.UsesMnemonic(cfg.Operator) {
+		fmt.Printf("hd path: %v\n", operator.HDPath(cfg.Operator))
+	}
+
+	oraclePrivKeyBytes, err := sgx.UnsealFromFile(cfg.OracleKey.File)
+	if err != nil {
+		log.Debugf("oracle key not available: %v", err)
+		return nil
+	}
+	oraclePubKey := secp256k1.PrivKeyFromBytes(oraclePrivKeyBytes).PubKey()
+	fmt.Printf("oracle pubkey: %v\n", hex.EncodeToString(oraclePubKey.SerializeCompressed()))
+	return nil
+}
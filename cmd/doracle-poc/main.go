@@ -1,62 +1,167 @@
 package main
 
 import (
+	"errors"
 	"flag"
+	"fmt"
 	"os"
-	"os/signal"
-	"syscall"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/cmd/doracle-poc/data"
+	"github.com/youngjoon-lee/doracle-poc/cmd/doracle-poc/keys"
 	"github.com/youngjoon-lee/doracle-poc/cmd/doracle-poc/mode"
+	"github.com/youngjoon-lee/doracle-poc/cmd/doracle-poc/oracle"
 	"github.com/youngjoon-lee/doracle-poc/pkg/app"
-	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/event"
 	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
 )
 
+// Exit codes, so that scripts can tell the result of a command.
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUsage        = 2
+	exitJoinRejected = 3
+	exitJoinTimeout  = 4
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"init", "generate the oracle key as the first oracle, and register it on chain", initCommand},
+	{"join", "join the existing oracle group, and wait until the oracle key is shared", nodeCommand("join", mode.Join)},
+	{"run", "run the oracle with the sealed oracle key", runCommand},
+	{"status", "print the status of a running oracle", oracle.Status},
+	{"keys show", "print the operator address (and the oracle pubkey if available)", keys.Show},
+	{"keys seal", "seal the operator key in the enclave", keys.SealOperatorKey},
+	{"attest", "verify a running oracle by a fresh attestation (in the SGX)", oracle.Attest},
+	{"verify-report", "verify a validation report signed by the oracle key", oracle.VerifyReport},
+	{"encrypt", "encrypt data to sell with the oracle pubkey", data.Encrypt},
+	{"decrypt", "decrypt purchased data", data.Decrypt},
+	{"remote-signer", "serve the operator key as a remote signer", keys.RemoteSigner},
+}
+
 func main() {
-	pTendermintRPC := flag.String("tm-rpc", "tcp://127.0.0.1:26657", "tendermint rpc addr")
-	pChainID := flag.String("chain-id", "dhub-1", "chain ID")
-	pOperatorMnemonic := flag.String("operator", "", "operator mnemonic")
-	pInit := flag.Bool("init", false, "run doracle with the init mode")
-	pJoin := flag.Bool("join", false, "run doracle with the join mode")
-	pDebug := flag.Bool("debug", false, "enable debug logs")
-	flag.Parse()
+	os.Exit(run(os.Args[1:]))
+}
 
-	if *pDebug {
-		log.SetLevel(log.DebugLevel)
+func run(args []string) int {
+	cmd, cmdArgs, ok := findCommand(args)
+	if !ok {
+		usage()
+		return exitUsage
 	}
 
-	app, err := app.NewApp(*pTendermintRPC, *pChainID, *pOperatorMnemonic)
-	if err != nil {
-		log.Fatalf("failed to init app: %v", err)
+	if err := cmd.run(cmdArgs); err != nil {
+		log.Errorf("failed to %v: %v", cmd.name, err)
+		return exitCode(err)
 	}
-	defer app.Close()
+	return exitOK
+}
 
-	if *pInit && *pJoin {
-		log.Fatal("do not use -init with -join")
-	} else if *pInit {
-		if err := mode.Init(app); err != nil {
-			log.Fatalf("failed to run the init mode: %v", err)
+func findCommand(args []string) (command, []string, bool) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], true
 		}
-	} else if *pJoin {
-		if err := mode.Join(app); err != nil {
-			log.Fatalf("failed to run the join mode: %v", err)
+	}
+	return command{}, nil, false
+}
+
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, event.ErrJoinRejected):
+		return exitJoinRejected
+	case errors.Is(err, mode.ErrJoinTimeout):
+		return exitJoinTimeout
+	default:
+		return exitError
+	}
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: doracle-poc <command> [flags]\n\nCommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-15v %v\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun 'doracle-poc <command> -h' for the flags of each command.\n")
+	fmt.Fprintf(os.Stderr, "\nExit codes:\n  %v: success\n  %v: error\n  %v: usage error\n  %v: join rejected\n  %v: join timed out\n",
+		exitOK, exitError, exitUsage, exitJoinRejected, exitJoinTimeout)
+}
+
+// nodeCommand returns a command which runs a mode of the oracle node, such as join.
+func nodeCommand(name string, modeFn func(app *app.App, cfg config.Config) error) func(args []string) error {
+	return func(args []string) error {
+		app, cfg, err := newApp(name, args, nil)
+		if err != nil {
+			return err
 		}
+		defer app.Close()
+
+		return modeFn(app, cfg)
 	}
+}
 
-	oraclePrivKeyBytes, err := sgx.UnsealFromFile(mode.OracleKeyFilePath)
+func initCommand(args []string) error {
+	var force bool
+	app, cfg, err := newApp("init", args, func(fs *flag.FlagSet) {
+		fs.BoolVar(&force, "force", false, "replace the existing oracle key (it is backed up)")
+	})
 	if err != nil {
-		log.Fatalf("failed to load and unseal oracle key: %v", err)
+		return err
 	}
+	defer app.Close()
 
-	app.SetOraclePrivKey(secp256k1.PrivKeyFromBytes(oraclePrivKeyBytes))
-	if err := app.SubscribeAll(); err != nil {
-		log.Fatalf("failed to subscribeAll: %v", err)
+	return mode.Init(app, cfg, force)
+}
+
+func runCommand(args []string) error {
+	app, cfg, err := newApp("run", args, nil)
+	if err != nil {
+		return err
 	}
+	defer app.Close()
+
+	return mode.Run(app, cfg)
+}
 
-	sigCh := make(chan os.Signal, 1)
-	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
-	<-sigCh
+// newApp loads the config from the flags of the node command, and creates an app.
+// extraFlags registers the flags specific to the command, if not nil.
+func newApp(name string, args []string, extraFlags func(fs *flag.FlagSet)) (*app.App, config.Config, error) {
+	cfg := config.Default()
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	pConfig := cfg.RegisterFlags(flags)
+	pDebug := flags.Bool("debug", false, "enable debug logs (same as -log-level debug)")
+	if extraFlags != nil {
+		extraFlags(flags)
+	}
+	if err := cfg.Load(flags, pConfig, args); err != nil {
+		return nil, config.Config{}, fmt.Errorf("failed to load config: %w", err)
+	}
 
-	log.Info("terminating the process")
+	if err := cfg.Log.ApplyLog(); err != nil {
+		return nil, config.Config{}, fmt.Errorf("failed to apply log config: %w", err)
+	}
+	if *pDebug {
+		log.SetLevel(log.DebugLevel)
+	}
+
+	if cfg.Attestation.MinSecurityVersion != 0 {
+		if err := sgx.RaiseMinSecurityVersion(cfg.Attestation.MinSecurityVersion); err != nil {
+			return nil, config.Config{}, fmt.Errorf("invalid attestation.min_security_version: %w", err)
+		}
+	}
+
+	app, err := app.NewApp(cfg)
+	if err != nil {
+		return nil, config.Config{}, fmt.Errorf("failed to init app: %w", err)
+	}
+	return app, cfg, nil
 }
@@ -0,0 +1,65 @@
+package mode
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+const diagnoseTimeout = 30 * time.Second
+
+// remediations are the hints for the reasons published by voters, which are the errors of sgx.VerifyRemoteReport.
+// Errors don't survive the chain, so the reasons are matched by the error messages.
+var remediations = []struct {
+	err  error
+	hint string
+}{
+	{sgx.ErrSignerID, "the binary is signed by a key other than the one the oracles trust. sign it with the key of the oracle group (see 'Build and sign'), or check that the oracles run the same release"},
+	{sgx.ErrSecurityVersion, "the security version (SVN) of the binary is lower than the oracles require. upgrade to the latest release"},
+	{sgx.ErrProductID, "the product ID of the binary differs from the one the oracles require. check productID in enclave.json"},
+	{sgx.ErrTCBStatus, "the TCB of this SGX platform is outdated. update the BIOS/microcode and the SGX platform software, then join again"},
+	{sgx.ErrReportData, "the SGX report doesn't bind the encryption key of the join. make sure the joiner and the oracles run compatible versions"},
+	{sgx.ErrReportVerification, "the SGX report couldn't be verified. check the quote provider (DCAP) configuration of this platform"},
+}
+
+// diagnoseJoin logs the votes cast on the join, and hints how to get the join approved.
+// It is best-effort, so failures are only logged.
+func diagnoseJoin(app *app.App, joinID uint64) {
+	ctx, cancel := context.WithTimeout(context.Background(), diagnoseTimeout)
+	defer cancel()
+
+	votes, err := app.QueryClient().Votes(ctx, joinID)
+	if err != nil {
+		log.Warnf("failed to fetch the votes on join %v: %v", joinID, err)
+		return
+	}
+	if len(votes) == 0 {
+		log.Warnf("no oracle voted on join %v. check that the oracles are running", joinID)
+		return
+	}
+
+	log.Infof("votes on join %v:", joinID)
+	hints := make(map[string]bool)
+	for _, vote := range votes {
+		reason := vote.Reason
+		if reason == "" {
+			reason = "no reason published"
+		}
+		log.Infof("  %v: %v (height %v, tx %v): %v", vote.Voter, vote.Option, vote.Height, vote.TxHash, reason)
+
+		if vote.Option != oracletypes.OptionNo {
+			continue
+		}
+		for _, r := range remediations {
+			if strings.Contains(vote.Reason, r.err.Error()) && !hints[r.hint] {
+				hints[r.hint] = true
+				log.Warnf("hint: %v", r.hint)
+			}
+		}
+	}
+}
@@ -7,20 +7,38 @@ import (
 	cosmossecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	log "github.com/sirupsen/logrus"
 	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
 	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
 	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
 )
 
-const OracleKeyFilePath = "/data/oracle-key.sealed"
+// Init generates the oracle key as the first oracle, and registers it on chain.
+// It refuses to replace an existing oracle key or an in-progress join unless force is set,
+// in which case the existing files are backed up rather than deleted.
+func Init(app *app.App, cfg config.Config, force bool) error {
+	state, err := inspectKeyState(cfg.OracleKey)
+	if err != nil && !force {
+		return fmt.Errorf("%w: use -force to replace it", err)
+	}
+	if state != keyStateNone && !force {
+		return fmt.Errorf("%w (state: %v): use -force to replace it", ErrOracleKeyExists, state)
+	}
+	if force {
+		if err := backupFile(cfg.OracleKey.File); err != nil {
+			return err
+		}
+		if err := backupFile(cfg.OracleKey.JoinStateFile); err != nil {
+			return err
+		}
+	}
 
-func Init(app *app.App) error {
 	oraclePrivKey, err := secp256k1.NewPrivKey()
 	if err != nil {
-		log.Fatalf("failed to generate oracle key: %v", err)
+		return fmt.Errorf("failed to generate oracle key: %w", err)
 	}
 
-	if err := sgx.SealToFile(oraclePrivKey.Serialize(), OracleKeyFilePath); err != nil {
-		log.Fatalf("failed to save oracle key: %v", err)
+	if err := sgx.SealToFile(oraclePrivKey.Serialize(), cfg.OracleKey.File); err != nil {
+		return fmt.Errorf("failed to save oracle key: %w", err)
 	}
 
 	oraclePubKey := &cosmossecp256k1.PubKey{
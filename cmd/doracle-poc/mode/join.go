@@ -3,17 +3,42 @@ package mode
 import (
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/btcsuite/btcd/btcec"
 	cosmossecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
 	log "github.com/sirupsen/logrus"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
 	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
 	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/event"
 	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
 	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
 )
 
-func Join(app *app.App) error {
+// ErrJoinTimeout is returned if the join result is not received until the join timeout.
+var ErrJoinTimeout = errors.New("join result not received in time")
+
+// Join joins the existing oracle group, and waits until the oracle key is shared.
+// If the oracle key already exists, it does nothing. If a join is in progress, it resumes waiting for its result.
+func Join(app *app.App, cfg config.Config) error {
+	state, err := inspectKeyState(cfg.OracleKey)
+	if err != nil {
+		return err
+	}
+
+	switch state {
+	case keyStateReady:
+		log.Infof("oracle key %s already exists. nothing to do", cfg.OracleKey.File)
+		return nil
+	case keyStateJoining:
+		return resumeJoin(app, cfg)
+	}
+
 	encPrivKey, err := secp256k1.NewPrivKey()
 	if err != nil {
 		return fmt.Errorf("failed to generate encryption key: %w", err)
@@ -36,11 +61,110 @@ func Join(app *app.App) error {
 		return fmt.Errorf("failed to execute join tx: %w", err)
 	}
 
-	log.Info("subscribing the join result...")
-	ev := event.NewJoinResultEvent(joinID, encPrivKey, OracleKeyFilePath)
-	if err := app.Subscriber().SubscribeOnce(context.Background(), ev); err != nil {
-		return fmt.Errorf("failed to subscribe once: %w", err)
+	// without the join state, the next run would submit another join while this one is pending
+	if err := saveJoinState(cfg.OracleKey.JoinStateFile, joinID, encPrivKey); err != nil {
+		return fmt.Errorf("join %v was submitted, but it cannot be resumed: %w. fix the problem and wait until join %v is closed before joining again", joinID, err, joinID)
+	}
+
+	return waitJoinResult(app, cfg, joinID, encPrivKey)
+}
+
+// resumeJoin resumes the join sealed in the join state file.
+// The join result may have been emitted while the process was down, so the join is queried first.
+func resumeJoin(app *app.App, cfg config.Config) error {
+	joinID, encPrivKey, err := loadJoinState(cfg.OracleKey.JoinStateFile)
+	if err != nil {
+		return err
+	}
+	log.Infof("resuming join %v", joinID)
+
+	join, err := app.QueryClient().Join(context.Background(), joinID)
+	if err != nil {
+		return err
+	}
+
+	if join.Status == oracletypes.JOIN_STATUS_PENDING {
+		return waitJoinResult(app, cfg, joinID, encPrivKey)
 	}
 
-	return nil
+	ev := event.NewJoinResultEvent(joinID, encPrivKey, cfg.OracleKey.File)
+	return finishJoin(app, cfg, joinID, ev.Apply(join.Status, join.TallyResult.YesValue))
+}
+
+// waitJoinResult waits for the result of the join until cfg.Join.Timeout, or until the process is interrupted.
+// While waiting, the join is queried periodically to log its vote tally,
+// and to handle the result even if the join_result event was missed.
+// The join state is kept if no result is received, so that the join can be resumed later.
+func waitJoinResult(app *app.App, cfg config.Config, joinID uint64, encPrivKey *btcec.PrivateKey) error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	if cfg.Join.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Join.Timeout)
+		defer cancel()
+	}
+
+	log.Infof("subscribing the result of join %v...", joinID)
+	ev := event.NewJoinResultEvent(joinID, encPrivKey, cfg.OracleKey.File)
+	subCtx, cancelSub := context.WithCancel(ctx)
+	defer cancelSub()
+	subErrCh := make(chan error, 1)
+	go func() {
+		subErrCh <- app.Subscriber().SubscribeOnce(subCtx, ev)
+	}()
+
+	startedAt := time.Now()
+	ticker := time.NewTicker(cfg.Join.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-subErrCh:
+			if err == nil {
+				return finishJoin(app, cfg, joinID, nil)
+			}
+			if ctx.Err() != nil {
+				return joinNotFinished(ctx, joinID, startedAt)
+			}
+			return finishJoin(app, cfg, joinID, fmt.Errorf("failed to subscribe once: %w", err))
+		case <-ticker.C:
+			join, err := app.QueryClient().Join(ctx, joinID)
+			if err != nil {
+				log.Warnf("failed to query the status of join %v: %v", joinID, err)
+				continue
+			}
+			if join.Status == oracletypes.JOIN_STATUS_PENDING {
+				log.Infof("waiting for the result of join %v: yes %v, no %v (%v elapsed)",
+					joinID, join.TallyResult.Yes, join.TallyResult.No, time.Since(startedAt).Round(time.Second))
+				continue
+			}
+
+			// the result is decided on chain, but the join_result event may not arrive
+			cancelSub()
+			if err := <-subErrCh; err == nil {
+				return finishJoin(app, cfg, joinID, nil)
+			}
+			return finishJoin(app, cfg, joinID, ev.Apply(join.Status, join.TallyResult.YesValue))
+		}
+	}
+}
+
+// finishJoin removes the join state if the join result was handled, whether it was approved or rejected.
+// If rejected, the votes on the join are logged for diagnosis.
+func finishJoin(app *app.App, cfg config.Config, joinID uint64, err error) error {
+	if err == nil || errors.Is(err, event.ErrJoinRejected) {
+		removeJoinState(cfg.OracleKey.JoinStateFile)
+	}
+	if errors.Is(err, event.ErrJoinRejected) {
+		diagnoseJoin(app, joinID)
+	}
+	return err
+}
+
+func joinNotFinished(ctx context.Context, joinID uint64, startedAt time.Time) error {
+	elapsed := time.Since(startedAt).Round(time.Second)
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%w: join %v is still pending after %v. run join again to resume waiting", ErrJoinTimeout, joinID, elapsed)
+	}
+	return fmt.Errorf("interrupted while waiting for the result of join %v. run join again to resume waiting", joinID)
 }
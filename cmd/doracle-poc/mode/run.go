@@ -0,0 +1,133 @@
+package mode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/api"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/datacache"
+	"github.com/youngjoon-lee/doracle-poc/pkg/rpc"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+	"github.com/youngjoon-lee/doracle-poc/pkg/validation"
+)
+
+const verifyOracleKeyTimeout = 30 * time.Second
+
+// Run runs the oracle with the oracle key sealed by the init or join mode, until the process is terminated.
+// If a join is in progress, it waits for the join result first.
+func Run(app *app.App, cfg config.Config) error {
+	state, err := inspectKeyState(cfg.OracleKey)
+	if err != nil {
+		return err
+	}
+	switch state {
+	case keyStateNone:
+		return fmt.Errorf("no oracle key in %s: run init or join first", cfg.OracleKey.File)
+	case keyStateJoining:
+		if err := resumeJoin(app, cfg); err != nil {
+			return err
+		}
+	}
+
+	oraclePrivKeyBytes, err := sgx.UnsealFromFile(cfg.OracleKey.File)
+	if err != nil {
+		return fmt.Errorf("failed to load and unseal oracle key: %w", err)
+	}
+
+	oraclePrivKey := secp256k1.PrivKeyFromBytes(oraclePrivKeyBytes)
+	err = verifyOracleKey(app, oraclePrivKey)
+	switch {
+	case err == nil:
+		app.SetOraclePrivKey(oraclePrivKey)
+		if err := app.SubscribeAll(); err != nil {
+			return fmt.Errorf("failed to subscribeAll: %w", err)
+		}
+	case errors.Is(err, ErrOracleKeyMismatch) && cfg.OracleKey.OnMismatch == config.OnKeyMismatchObserve:
+		log.Warnf("%v. running as an observer without the oracle key", err)
+		app.SetObserver(true)
+	default:
+		return err
+	}
+
+	if cfg.API.Addr != "" || cfg.API.GRPCAddr != "" {
+		dataCache, err := datacache.New(cfg.DataCache)
+		if err != nil {
+			return fmt.Errorf("failed to init data cache: %w", err)
+		}
+		app.SetDataCache(dataCache)
+
+		if cfg.Validation.RulesFile != "" {
+			rules, err := validation.LoadRules(cfg.Validation.RulesFile)
+			if err != nil {
+				return fmt.Errorf("failed to load validation rules: %w", err)
+			}
+			app.SetValidationRules(rules)
+		}
+	}
+
+	if cfg.API.Addr != "" {
+		apiServer, err := api.NewServer(app, cfg.API.Addr, cfg.API.AttestedTLS)
+		if err != nil {
+			return fmt.Errorf("failed to init API server: %w", err)
+		}
+		if err := apiServer.Start(); err != nil {
+			return fmt.Errorf("failed to start API server: %w", err)
+		}
+		defer apiServer.Close()
+	}
+
+	if cfg.API.GRPCAddr != "" {
+		grpcServer, err := rpc.NewServer(app, cfg.API.GRPCAddr, cfg.API.AttestedTLS)
+		if err != nil {
+			return fmt.Errorf("failed to init gRPC server: %w", err)
+		}
+		if err := grpcServer.Start(); err != nil {
+			return fmt.Errorf("failed to start gRPC server: %w", err)
+		}
+		defer grpcServer.Close()
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGTERM, syscall.SIGINT)
+	select {
+	case <-sigCh:
+	case err := <-app.Subscriber().Failed():
+		return fmt.Errorf("failed to handle events: %w", err)
+	}
+
+	log.Info("terminating the process")
+	return nil
+}
+
+// ErrOracleKeyMismatch is returned if the sealed oracle key is not the one registered on chain.
+var ErrOracleKeyMismatch = errors.New("oracle key doesn't match the oracle pubkey on chain")
+
+// verifyOracleKey checks that the oracle key corresponds to the oracle pubkey on chain,
+// so that a stale key or a key of another chain is never used to vote or to encrypt the oracle key to joiners.
+func verifyOracleKey(app *app.App, oraclePrivKey *btcec.PrivateKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), verifyOracleKeyTimeout)
+	defer cancel()
+
+	onChainPubKey, err := app.QueryClient().OraclePubKey(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to verify oracle key: %w", err)
+	}
+
+	pubKey := oraclePrivKey.PubKey()
+	if !pubKey.IsEqual(onChainPubKey) {
+		return fmt.Errorf("%w: sealed %x, on chain %x",
+			ErrOracleKeyMismatch, pubKey.SerializeCompressed(), onChainPubKey.SerializeCompressed())
+	}
+	log.Infof("oracle key matches the oracle pubkey on chain: %x", pubKey.SerializeCompressed())
+	return nil
+}
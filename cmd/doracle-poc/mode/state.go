@@ -0,0 +1,134 @@
+package mode
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+// ErrOracleKeyExists is returned if init would overwrite an existing oracle key without -force.
+var ErrOracleKeyExists = errors.New("oracle key already exists")
+
+type keyState int
+
+const (
+	// keyStateNone means that neither the oracle key nor an in-progress join exists.
+	keyStateNone keyState = iota
+	// keyStateJoining means that a join was submitted, but its result has not been received yet.
+	keyStateJoining
+	// keyStateReady means that a valid oracle key is sealed.
+	keyStateReady
+)
+
+func (s keyState) String() string {
+	switch s {
+	case keyStateJoining:
+		return "joining"
+	case keyStateReady:
+		return "ready"
+	default:
+		return "none"
+	}
+}
+
+// inspectKeyState returns the state of the oracle key on the disk.
+// An oracle key file which cannot be unsealed is an error, rather than keyStateNone,
+// so that it is never overwritten by mistake.
+func inspectKeyState(cfg config.OracleKeyConfig) (keyState, error) {
+	exists, err := fileExists(cfg.File)
+	if err != nil {
+		return keyStateNone, err
+	}
+	if exists {
+		keyBytes, err := sgx.UnsealFromFile(cfg.File)
+		if err != nil {
+			return keyStateNone, fmt.Errorf("oracle key %s exists but is not usable: %w", cfg.File, err)
+		}
+		if len(keyBytes) != btcec.PrivKeyBytesLen {
+			return keyStateNone, fmt.Errorf("oracle key %s exists but is not usable: invalid length %v", cfg.File, len(keyBytes))
+		}
+		return keyStateReady, nil
+	}
+
+	exists, err = fileExists(cfg.JoinStateFile)
+	if err != nil {
+		return keyStateNone, err
+	}
+	if exists {
+		return keyStateJoining, nil
+	}
+	return keyStateNone, nil
+}
+
+// joinState is an in-progress join, which is sealed until the oracle key is received.
+type joinState struct {
+	JoinID     uint64 `json:"join_id"`
+	EncPrivKey []byte `json:"enc_priv_key"`
+}
+
+func saveJoinState(filePath string, joinID uint64, encPrivKey *btcec.PrivateKey) error {
+	data, err := json.Marshal(joinState{JoinID: joinID, EncPrivKey: encPrivKey.Serialize()})
+	if err != nil {
+		return fmt.Errorf("failed to marshal join state: %w", err)
+	}
+	if err := sgx.SealToFile(data, filePath); err != nil {
+		return fmt.Errorf("failed to save join state: %w", err)
+	}
+	return nil
+}
+
+func loadJoinState(filePath string) (uint64, *btcec.PrivateKey, error) {
+	data, err := sgx.UnsealFromFile(filePath)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to load join state: %w", err)
+	}
+
+	var state joinState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return 0, nil, fmt.Errorf("failed to unmarshal join state: %w", err)
+	}
+	if len(state.EncPrivKey) != btcec.PrivKeyBytesLen {
+		return 0, nil, fmt.Errorf("invalid encryption key in join state")
+	}
+	return state.JoinID, secp256k1.PrivKeyFromBytes(state.EncPrivKey), nil
+}
+
+func removeJoinState(filePath string) {
+	if err := os.Remove(filePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Warnf("failed to remove join state %s: %v", filePath, err)
+	}
+}
+
+// backupFile renames the file to <file>.<timestamp>.bak if it exists, instead of deleting it.
+func backupFile(filePath string) error {
+	exists, err := fileExists(filePath)
+	if err != nil || !exists {
+		return err
+	}
+
+	backupPath := fmt.Sprintf("%s.%s.bak", filePath, time.Now().UTC().Format("20060102T150405Z"))
+	if err := os.Rename(filePath, backupPath); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", filePath, err)
+	}
+	log.Warnf("%s is moved to %s", filePath, backupPath)
+	return nil
+}
+
+func fileExists(filePath string) (bool, error) {
+	_, err := os.Stat(filePath)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to stat %s: %w", filePath, err)
+}
@@ -0,0 +1,52 @@
+package oracle
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/query"
+)
+
+// ErrOraclePubKeyMismatch is returned if the attested oracle public key differs from the one registered on chain.
+var ErrOraclePubKeyMismatch = errors.New("attested oracle pubkey doesn't match the one on chain")
+
+// Attest requests a fresh attestation from a running oracle and verifies it.
+// The report is verified by sgx.VerifyRemoteReport, so this must be run in the SGX.
+func Attest(args []string) error {
+	flags := flag.NewFlagSet("attest", flag.ExitOnError)
+	pAPI := flags.String("api", "http://127.0.0.1:8080", "base URL of the oracle API")
+	pTendermintRPC := flags.String("tm-rpc", "", "tendermint rpc addr to compare the attested oracle pubkey with the one on chain (optional)")
+	flags.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	oraclePubKey, err := newAPIClient(*pAPI, false).Attest(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to attest: %w", err)
+	}
+	oraclePubKeyHex := hex.EncodeToString(oraclePubKey.SerializeCompressed())
+	log.Infof("attestation verified")
+
+	if *pTendermintRPC != "" {
+		queryClient, err := query.NewClient(*pTendermintRPC)
+		if err != nil {
+			return fmt.Errorf("failed to init query client: %w", err)
+		}
+		onChainPubKey, err := queryClient.OraclePubKey(ctx)
+		if err != nil {
+			return err
+		}
+		if !onChainPubKey.IsEqual(oraclePubKey) {
+			return fmt.Errorf("%w: %v != %v", ErrOraclePubKeyMismatch, oraclePubKeyHex, hex.EncodeToString(onChainPubKey.SerializeCompressed()))
+		}
+		log.Infof("attested oracle pubkey matches the one on chain")
+	}
+
+	fmt.Printf("oracle pubkey: %v\n", oraclePubKeyHex)
+	return nil
+}
@@ -0,0 +1,47 @@
+package oracle
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/youngjoon-lee/doracle-poc/pkg/api"
+)
+
+const requestTimeout = 30 * time.Second
+
+// Status prints the status of a running oracle retrieved from its API.
+func Status(args []string) error {
+	flags := flag.NewFlagSet("status", flag.ExitOnError)
+	pAPI := flags.String("api", "http://127.0.0.1:8080", "base URL of the oracle API")
+	pAttestedTLS := flags.Bool("attested-tls", false, "verify the oracle by its attested TLS certificate (must be run in the SGX)")
+	flags.Parse(args)
+
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+
+	status, err := newAPIClient(*pAPI, *pAttestedTLS).Status(ctx)
+	if err != nil {
+		return err
+	}
+	return printJSON(status)
+}
+
+func newAPIClient(baseURL string, attestedTLS bool) *api.Client {
+	if attestedTLS {
+		return api.NewAttestedTLSClient(baseURL)
+	}
+	return api.NewClient(baseURL, nil)
+}
+
+func printJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to print: %w", err)
+	}
+	return nil
+}
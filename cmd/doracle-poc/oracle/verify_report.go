@@ -0,0 +1,86 @@
+package oracle
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/query"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/validation"
+)
+
+// VerifyReport verifies a validation report signed by the oracle key, and prints the report.
+// It doesn't need to be run in the SGX.
+func VerifyReport(args []string) error {
+	flags := flag.NewFlagSet("verify-report", flag.ExitOnError)
+	pReport := flags.String("report", "", "JSON file of the signed report")
+	pChainID := flags.String("chain-id", "dhub-1", "chain ID which the report must be signed for")
+	pTendermintRPC := flags.String("tm-rpc", "tcp://127.0.0.1:26657", "tendermint rpc addr to get the oracle pubkey")
+	pPubKey := flags.String("pubkey", "", "hex-encoded oracle pubkey, instead of the one on chain")
+	pRequestID := flags.String("request-id", "", "request ID which the report must be computed for (not checked if empty)")
+	pRules := flags.String("rules", "", "JSON file of the rules which the report must be computed with (not checked if empty)")
+	flags.Parse(args)
+
+	if *pReport == "" {
+		return fmt.Errorf("-report must be specified")
+	}
+	bz, err := ioutil.ReadFile(*pReport)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %w", *pReport, err)
+	}
+	var signedReport validation.SignedReport
+	if err := json.Unmarshal(bz, &signedReport); err != nil {
+		return fmt.Errorf("failed to parse %v: %w", *pReport, err)
+	}
+
+	oraclePubKey, err := oraclePubKey(*pTendermintRPC, *pPubKey)
+	if err != nil {
+		return err
+	}
+
+	report, err := signedReport.Verify(oraclePubKey, *pChainID)
+	if err != nil {
+		return fmt.Errorf("failed to verify report: %w", err)
+	}
+
+	if *pRequestID != "" && report.RequestID != *pRequestID {
+		return fmt.Errorf("report was computed for another request: %v", report.RequestID)
+	}
+	if *pRules != "" {
+		rules, err := validation.LoadRules(*pRules)
+		if err != nil {
+			return err
+		}
+		rulesHash, err := rules.Hash()
+		if err != nil {
+			return err
+		}
+		if report.RulesHash != rulesHash {
+			return fmt.Errorf("report was computed with other rules: %v", report.RulesHash)
+		}
+	}
+	return printJSON(report)
+}
+
+func oraclePubKey(tendermintRPCAddr, pubKeyHex string) (*btcec.PublicKey, error) {
+	if pubKeyHex != "" {
+		pubKeyBytes, err := hex.DecodeString(pubKeyHex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode pubkey: %w", err)
+		}
+		return secp256k1.PubKeyFromBytes(pubKeyBytes)
+	}
+
+	queryClient, err := query.NewClient(tendermintRPCAddr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init query client: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), requestTimeout)
+	defer cancel()
+	return queryClient.OraclePubKey(ctx)
+}
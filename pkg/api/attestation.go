@@ -0,0 +1,55 @@
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+type AttestationResponse struct {
+	OraclePubKey string `json:"oracle_pub_key"`
+	Report       []byte `json:"report"`
+}
+
+func (s *Server) handleAttestation(w http.ResponseWriter, r *http.Request) {
+	nonce, err := hex.DecodeString(r.URL.Query().Get("nonce"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid nonce: %w", err))
+		return
+	}
+
+	oraclePubKey, report, err := s.app.Attest(nonce)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, AttestationResponse{
+		OraclePubKey: hex.EncodeToString(oraclePubKey.SerializeCompressed()),
+		Report:       report,
+	})
+}
+
+// VerifyAttestation verifies the attestation response for the nonce sent by the client,
+// and returns the oracle public key attested by the report.
+// It must be called in the SGX unless built with the eclient tag (see sgx.VerifyRemoteReportAsClient).
+func VerifyAttestation(res AttestationResponse, nonce []byte) (*btcec.PublicKey, error) {
+	pubKeyBytes, err := hex.DecodeString(res.OraclePubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode oracle pubkey: %w", err)
+	}
+	oraclePubKey, err := secp256k1.PubKeyFromBytes(pubKeyBytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oracle pubkey: %w", err)
+	}
+
+	if err := sgx.VerifyRemoteReportAsClient(res.Report, app.AttestationReportData(nonce, oraclePubKey)); err != nil {
+		return nil, fmt.Errorf("failed to verify report: %w", err)
+	}
+	return oraclePubKey, nil
+}
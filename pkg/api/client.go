@@ -0,0 +1,123 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/oraclesig"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+// Client is a client of the oracle API for consumers who want to verify an oracle before sending data to it.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewAttestedTLSClient creates a client for the server served over attested TLS.
+// The server is verified by its certificate, so no separate attestation call is needed.
+// It must be used in the SGX unless built with the eclient tag.
+func NewAttestedTLSClient(baseURL string) *Client {
+	return NewClient(baseURL, &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: sgx.CreateAttestedClientTLSConfig(),
+		},
+	})
+}
+
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:    baseURL,
+		httpClient: httpClient,
+	}
+}
+
+// Attest requests a fresh attestation with a random nonce, and returns the oracle public key verified by the report.
+// It must be called in the SGX unless built with the eclient tag (see VerifyAttestation).
+func (c *Client) Attest(ctx context.Context) (*btcec.PublicKey, error) {
+	nonce := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	var res AttestationResponse
+	if err := c.get(ctx, "/attestation?nonce="+url.QueryEscape(hex.EncodeToString(nonce)), &res); err != nil {
+		return nil, err
+	}
+
+	return VerifyAttestation(res, nonce)
+}
+
+func (c *Client) Status(ctx context.Context) (StatusResponse, error) {
+	var res StatusResponse
+	if err := c.get(ctx, "/status", &res); err != nil {
+		return StatusResponse{}, err
+	}
+	return res, nil
+}
+
+// SubmitData submits the ciphertext for the on-chain request directly to the oracle, signed by the seller's key.
+func (c *Client) SubmitData(ctx context.Context, chainID, requestID string, ciphertext []byte, sellerPrivKey *btcec.PrivateKey) error {
+	timestamp := time.Now().Unix()
+	sig, err := oraclesig.Sign(sellerPrivKey, oraclesig.DomainDataSubmission, chainID, app.SubmissionPayload(requestID, timestamp, ciphertext))
+	if err != nil {
+		return fmt.Errorf("failed to sign submission: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/data/"+url.PathEscape(requestID), bytes.NewReader(ciphertext))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set(HeaderSellerPubKey, hex.EncodeToString(sellerPrivKey.PubKey().SerializeCompressed()))
+	req.Header.Set(HeaderSignature, hex.EncodeToString(sig))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+
+	var res submissionResponse
+	return c.do(req, &res)
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	return c.do(req, v)
+}
+
+func (c *Client) do(req *http.Request, v interface{}) error {
+	path := req.URL.Path
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to request %v: %w", path, err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK && res.StatusCode != http.StatusCreated {
+		var errRes errorResponse
+		if err := json.NewDecoder(res.Body).Decode(&errRes); err != nil {
+			return fmt.Errorf("request %v failed: status:%v", path, res.StatusCode)
+		}
+		return fmt.Errorf("request %v failed: status:%v: %v", path, res.StatusCode, errRes.Error)
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/datacache"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+const shutdownTimeout = 5 * time.Second
+
+// Server serves the HTTP API for operators and dashboards to inspect the running oracle.
+type Server struct {
+	app        *app.App
+	identity   *sgx.Identity
+	httpServer *http.Server
+	tlsConfig  *tls.Config
+}
+
+// NewServer creates a server. If attestedTLS is true, the server is served over TLS
+// with a certificate bound to a SGX report (see sgx.CreateAttestedServerTLSConfig).
+func NewServer(app *app.App, listenAddr string, attestedTLS bool) (*Server, error) {
+	s := &Server{app: app}
+
+	if attestedTLS {
+		tlsConfig, err := sgx.CreateAttestedServerTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+		s.tlsConfig = tlsConfig
+	}
+
+	identity, err := sgx.SelfIdentity()
+	if err != nil {
+		log.Warnf("failed to get enclave identity: %v", err)
+	} else {
+		s.identity = &identity
+	}
+
+	s.httpServer = &http.Server{
+		Addr:              listenAddr,
+		Handler:           s.router(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s, nil
+}
+
+func (s *Server) router() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc("/status", s.handleStatus).Methods(http.MethodGet)
+	r.HandleFunc("/oracle/pubkey", s.handleOraclePubKey).Methods(http.MethodGet)
+	r.HandleFunc("/attestation", s.handleAttestation).Methods(http.MethodGet)
+	r.HandleFunc("/data/{request_id}", s.handleSubmitData).Methods(http.MethodPost)
+	return r
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.httpServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen %v: %w", s.httpServer.Addr, err)
+	}
+	if s.tlsConfig != nil {
+		listener = tls.NewListener(listener, s.tlsConfig)
+	}
+
+	go func() {
+		if err := s.httpServer.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Errorf("API server stopped: %v", err)
+		}
+	}()
+
+	log.Infof("API server listening on %v (attested TLS: %v)", s.httpServer.Addr, s.tlsConfig != nil)
+	return nil
+}
+
+func (s *Server) Close() {
+	log.Info("stopping API server...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.httpServer.Shutdown(ctx); err != nil {
+		log.Errorf("failed to shutdown API server: %v", err)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("failed to write response: %v", err)
+	}
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+// errorStatus maps errors returned by the app to HTTP status codes.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, app.ErrInvalidRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, app.ErrUnauthenticated):
+		return http.StatusUnauthorized
+	case errors.Is(err, app.ErrRateLimited):
+		return http.StatusTooManyRequests
+	case errors.Is(err, app.ErrOracleKeyNotLoaded), errors.Is(err, app.ErrDataSubmissionDisabled):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, datacache.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, datacache.ErrExists):
+		return http.StatusConflict
+	case errors.Is(err, datacache.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, datacache.ErrCacheFull):
+		return http.StatusInsufficientStorage
+	case errors.Is(err, datacache.ErrQuota):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/event"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+// StatusResponse is the response of GET /status.
+type StatusResponse struct {
+	ChainID         string               `json:"chain_id"`
+	OperatorAddress string               `json:"operator_address"`
+	OraclePubKey    string               `json:"oracle_pub_key"`
+	Enclave         *sgx.Identity        `json:"enclave"`
+	Subscriptions   []event.Subscription `json:"subscriptions"`
+	LastHeight      int64                `json:"last_height"`
+	PendingTxs      int64                `json:"pending_txs"`
+	Observer        bool                 `json:"observer"`
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	txExecutor := s.app.TxExecutor()
+	subscriber := s.app.Subscriber()
+
+	writeJSON(w, http.StatusOK, StatusResponse{
+		ChainID:         txExecutor.ChainID(),
+		OperatorAddress: txExecutor.Signer().String(),
+		OraclePubKey:    s.oraclePubKeyHex(),
+		Enclave:         s.identity,
+		Subscriptions:   subscriber.Subscriptions(),
+		LastHeight:      subscriber.LastHeight(),
+		PendingTxs:      txExecutor.PendingTxs(),
+		Observer:        s.app.Observer(),
+	})
+}
+
+type oraclePubKeyResponse struct {
+	PubKey string `json:"pub_key"`
+}
+
+func (s *Server) handleOraclePubKey(w http.ResponseWriter, _ *http.Request) {
+	pubKey := s.oraclePubKeyHex()
+	if pubKey == "" {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("oracle key not loaded"))
+		return
+	}
+	writeJSON(w, http.StatusOK, oraclePubKeyResponse{PubKey: pubKey})
+}
+
+func (s *Server) oraclePubKeyHex() string {
+	privKey := s.app.OraclePrivKey()
+	if privKey == nil {
+		return ""
+	}
+	return hex.EncodeToString(privKey.PubKey().SerializeCompressed())
+}
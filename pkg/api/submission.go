@@ -0,0 +1,71 @@
+package api
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	HeaderSellerPubKey = "X-Seller-PubKey"
+	HeaderSignature    = "X-Signature"
+	// HeaderTimestamp is the time of the submission in unix seconds, which is covered by the signature.
+	HeaderTimestamp = "X-Timestamp"
+)
+
+type submissionResponse struct {
+	RequestID string    `json:"request_id"`
+	Seller    string    `json:"seller"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// handleSubmitData receives a ciphertext for an on-chain request directly from the seller,
+// and keeps it until the TTL of the cache passes.
+func (s *Server) handleSubmitData(w http.ResponseWriter, r *http.Request) {
+	cache := s.app.DataCache()
+	if cache == nil {
+		writeError(w, http.StatusServiceUnavailable, fmt.Errorf("data submission disabled"))
+		return
+	}
+
+	requestID := mux.Vars(r)["request_id"]
+
+	sellerPubKey, err := hex.DecodeString(r.Header.Get(HeaderSellerPubKey))
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid %v: %w", HeaderSellerPubKey, err))
+		return
+	}
+	sig, err := hex.DecodeString(r.Header.Get(HeaderSignature))
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid %v: %w", HeaderSignature, err))
+		return
+	}
+	timestamp, err := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+	if err != nil {
+		writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid %v: %w", HeaderTimestamp, err))
+		return
+	}
+
+	ciphertext, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, int64(cache.MaxEntrySize())))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("failed to read body: %w", err))
+		return
+	}
+
+	seller, expiresAt, err := s.app.SubmitData(requestID, timestamp, sellerPubKey, sig, ciphertext)
+	if err != nil {
+		writeError(w, errorStatus(err), err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, submissionResponse{
+		RequestID: requestID,
+		Seller:    seller,
+		ExpiresAt: expiresAt,
+	})
+}
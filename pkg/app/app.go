@@ -1,36 +1,66 @@
 package app
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"sync"
+	"time"
 
 	"github.com/btcsuite/btcd/btcec"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	dhubapp "github.com/youngjoon-lee/dhub/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/datacache"
 	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/event"
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/query"
 	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/tx"
-	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/operator"
+	"github.com/youngjoon-lee/doracle-poc/pkg/oraclesig"
+	"github.com/youngjoon-lee/doracle-poc/pkg/validation"
+)
+
+var (
+	ErrOracleKeyNotLoaded     = errors.New("oracle key not loaded")
+	ErrDataSubmissionDisabled = errors.New("data submission disabled")
+	ErrValidationDisabled     = errors.New("validation disabled")
+	ErrInvalidRequest         = errors.New("invalid request")
+	ErrUnauthenticated        = errors.New("unauthenticated")
+	ErrRateLimited            = errors.New("rate limited")
 )
 
 type App struct {
 	oraclePrivKey *btcec.PrivateKey
+	observer      bool
 	txExecutor    tx.Executor
+	queryClient   query.Client
 	subscriber    *event.Subscriber
+	dataCache     *datacache.Cache
+	rules         *validation.Rules
+
+	attestationMtx         sync.Mutex
+	attestationWindowStart time.Time
+	attestationCount       int
 }
 
-func NewApp(tendermintRPCAddr, chainID, operatorMnemonic string) (*App, error) {
-	setDHubConfig()
+func NewApp(cfg config.Config) (*App, error) {
+	SetDHubConfig()
 
-	operatorPrivKey, operatorAddr, err := secp256k1.PrivateKeyFromMnemonic(operatorMnemonic)
+	operatorSigner, err := operator.LoadSigner(cfg.Operator)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get private key from mnemonic: %w", err)
+		return nil, fmt.Errorf("failed to load operator key: %w", err)
 	}
 
-	txExecutor, err := tx.NewExecutor(tendermintRPCAddr, chainID, operatorAddr, operatorPrivKey)
+	fees, err := cfg.Tx.FeeCoins()
+	if err != nil {
+		return nil, err
+	}
+	txExecutor, err := tx.NewExecutor(cfg.TendermintRPC, cfg.ChainID, operatorSigner, cfg.Tx.GasLimit, fees)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init tx executor: %w", err)
 	}
 
-	subscriber, err := event.NewSubscriber(tendermintRPCAddr)
+	subscriber, err := event.NewSubscriber(cfg.TendermintRPC)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init subscriber: %w", err)
 	}
@@ -42,6 +72,7 @@ func NewApp(tendermintRPCAddr, chainID, operatorMnemonic string) (*App, error) {
 	return &App{
 		oraclePrivKey: nil,
 		txExecutor:    txExecutor,
+		queryClient:   query.NewClientFromContext(txExecutor.Context()),
 		subscriber:    subscriber,
 	}, nil
 }
@@ -58,10 +89,55 @@ func (app *App) OraclePrivKey() *btcec.PrivateKey {
 	return app.oraclePrivKey
 }
 
+// SetObserver marks the app as running without the oracle key, because the sealed key is not usable on the chain.
+func (app *App) SetObserver(observer bool) {
+	app.observer = observer
+}
+
+func (app *App) Observer() bool {
+	return app.observer
+}
+
+// SetDataCache enables the data submitted directly by sellers to be kept in the cache.
+func (app *App) SetDataCache(dataCache *datacache.Cache) {
+	app.dataCache = dataCache
+}
+
+func (app *App) DataCache() *datacache.Cache {
+	return app.dataCache
+}
+
+// SetValidationRules enables submitted data to be validated with the rules.
+// The rules are fixed by the oracle, so that callers cannot get a report computed with laxer rules.
+func (app *App) SetValidationRules(rules validation.Rules) {
+	app.rules = &rules
+}
+
+// Sign signs an oracle output with the oracle key, so that consumers know the output was produced in the enclave.
+func (app *App) Sign(domain string, payload []byte) ([]byte, error) {
+	if app.oraclePrivKey == nil {
+		return nil, ErrOracleKeyNotLoaded
+	}
+	return oraclesig.Sign(app.oraclePrivKey, domain, app.txExecutor.ChainID(), payload)
+}
+
+// Validate validates the data encrypted with the oracle public key for the request, and returns the report signed by the oracle key.
+func (app *App) Validate(encrypted io.Reader, requestID string, rules validation.Rules) (validation.SignedReport, error) {
+	if app.oraclePrivKey == nil {
+		return validation.SignedReport{}, ErrOracleKeyNotLoaded
+	}
+	return validation.Validate(encrypted, app.oraclePrivKey, app.txExecutor.ChainID(), requestID, rules)
+}
+
 func (app *App) TxExecutor() tx.Executor {
 	return app.txExecutor
 }
 
+// QueryClient returns the client querying the oracle module, which shares the RPC client of the tx executor.
+func (app *App) QueryClient() query.Client {
+	return app.queryClient
+}
+
 func (app *App) Subscriber() *event.Subscriber {
 	return app.subscriber
 }
@@ -81,6 +157,13 @@ func (app *App) events() []event.Event {
 	}
 }
 
+var dhubConfigOnce sync.Once
+
+// SetDHubConfig sets the bech32 prefixes of dhub to the global SDK config. It can be called multiple times.
+func SetDHubConfig() {
+	dhubConfigOnce.Do(setDHubConfig)
+}
+
 func setDHubConfig() {
 	accountAddressPrefix := dhubapp.AccountAddressPrefix
 
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+const (
+	MinNonceSize = 16
+	MaxNonceSize = 64
+
+	// The attestation endpoint is not authenticated, and generating a report is expensive,
+	// so at most maxAttestationsPerWindow reports are generated in each attestationWindow.
+	attestationWindow        = time.Minute
+	maxAttestationsPerWindow = 30
+)
+
+// AttestationReportData returns the data to be put in the report: SHA-256(nonce) | SHA-256(oracle pubkey).
+// The nonce proves the freshness of the report, and the pubkey hash proves that the oracle key is held in the enclave.
+func AttestationReportData(nonce []byte, oraclePubKey *btcec.PublicKey) []byte {
+	nonceHash := sha256.Sum256(nonce)
+	pubKeyHash := sha256.Sum256(oraclePubKey.SerializeCompressed())
+	return append(nonceHash[:], pubKeyHash[:]...)
+}
+
+// Attest generates a SGX report bound to the nonce and the oracle public key.
+func (app *App) Attest(nonce []byte) (*btcec.PublicKey, []byte, error) {
+	if len(nonce) < MinNonceSize || len(nonce) > MaxNonceSize {
+		return nil, nil, fmt.Errorf("%w: nonce must be %v-%v bytes", ErrInvalidRequest, MinNonceSize, MaxNonceSize)
+	}
+	if app.oraclePrivKey == nil {
+		return nil, nil, ErrOracleKeyNotLoaded
+	}
+	oraclePubKey := app.oraclePrivKey.PubKey()
+
+	// generating reports is expensive, so they are generated one at a time
+	app.attestationMtx.Lock()
+	defer app.attestationMtx.Unlock()
+
+	now := time.Now()
+	if now.Sub(app.attestationWindowStart) >= attestationWindow {
+		app.attestationWindowStart = now
+		app.attestationCount = 0
+	}
+	if app.attestationCount >= maxAttestationsPerWindow {
+		retryAfter := app.attestationWindowStart.Add(attestationWindow).Sub(now)
+		return nil, nil, fmt.Errorf("%w: too many attestations, retry after %v", ErrRateLimited, retryAfter.Round(time.Second))
+	}
+	app.attestationCount++
+
+	report, err := sgx.GenerateRemotePeport(AttestationReportData(nonce, oraclePubKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate report: %w", err)
+	}
+	return oraclePubKey, report, nil
+}
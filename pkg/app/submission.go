@@ -0,0 +1,104 @@
+package app
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strconv"
+	"time"
+
+	cosmossecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/oraclesig"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/validation"
+)
+
+// SubmissionWindow is how far the timestamp of a submission may be from the clock of the oracle.
+// Cache entries outlive the window (see config.MinDataTTL), so a replayed submission is refused as a duplicate
+// while its timestamp is accepted, and by the timestamp after that.
+const SubmissionWindow = config.MinDataTTL / 2
+
+// SubmissionPayload returns the payload to be signed by the seller for oraclesig.DomainDataSubmission.
+// timestamp is in unix seconds.
+func SubmissionPayload(requestID string, timestamp int64, ciphertext []byte) []byte {
+	return append(ValidationRequestPayload(requestID, timestamp), ciphertext...)
+}
+
+// ValidationRequestPayload returns the payload to be signed by the seller for oraclesig.DomainValidationRequest.
+// timestamp is in unix seconds.
+func ValidationRequestPayload(requestID string, timestamp int64) []byte {
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(timestamp))
+
+	payload := make([]byte, 0, len(requestID)+1+len(ts))
+	payload = append(payload, requestID...)
+	payload = append(payload, 0)
+	return append(payload, ts[:]...)
+}
+
+// SubmitData verifies the signature of the seller, and keeps the ciphertext for the on-chain request
+// until the TTL of the cache passes. It returns the seller address and when the ciphertext expires.
+// The data is not processed when the sell-data event arrives yet, since the chain has no sell-data event.
+// It is used only by ValidateSubmission for now.
+func (app *App) SubmitData(requestID string, timestamp int64, sellerPubKeyBytes, sig, ciphertext []byte) (string, time.Time, error) {
+	if app.dataCache == nil {
+		return "", time.Time{}, ErrDataSubmissionDisabled
+	}
+	if _, err := strconv.ParseUint(requestID, 10, 64); err != nil {
+		return "", time.Time{}, fmt.Errorf("%w: invalid request ID: %v", ErrInvalidRequest, requestID)
+	}
+
+	payload := SubmissionPayload(requestID, timestamp, ciphertext)
+	seller, err := app.verifySeller(oraclesig.DomainDataSubmission, payload, timestamp, sellerPubKeyBytes, sig)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiresAt, err := app.dataCache.Put(requestID, seller, ciphertext)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return seller, expiresAt, nil
+}
+
+// ValidateSubmission validates the data submitted by the seller for the request with the rules of the oracle,
+// and returns the report signed by the oracle key. Only the seller who submitted the data can request it.
+// The data is kept in the cache.
+func (app *App) ValidateSubmission(requestID string, timestamp int64, sellerPubKeyBytes, sig []byte) (validation.SignedReport, error) {
+	if app.dataCache == nil {
+		return validation.SignedReport{}, ErrDataSubmissionDisabled
+	}
+	if app.rules == nil {
+		return validation.SignedReport{}, ErrValidationDisabled
+	}
+
+	payload := ValidationRequestPayload(requestID, timestamp)
+	seller, err := app.verifySeller(oraclesig.DomainValidationRequest, payload, timestamp, sellerPubKeyBytes, sig)
+	if err != nil {
+		return validation.SignedReport{}, err
+	}
+
+	ciphertext, err := app.dataCache.Get(requestID, seller)
+	if err != nil {
+		return validation.SignedReport{}, err
+	}
+	return app.Validate(bytes.NewReader(ciphertext), requestID, *app.rules)
+}
+
+// verifySeller verifies the signature of the seller over the timestamped payload, and returns the seller address.
+func (app *App) verifySeller(domain string, payload []byte, timestamp int64, sellerPubKeyBytes, sig []byte) (string, error) {
+	if skew := time.Since(time.Unix(timestamp, 0)); skew > SubmissionWindow || skew < -SubmissionWindow {
+		return "", fmt.Errorf("%w: timestamp %v is not within %v", ErrUnauthenticated, timestamp, SubmissionWindow)
+	}
+
+	sellerPubKey, err := secp256k1.PubKeyFromBytes(sellerPubKeyBytes)
+	if err != nil {
+		return "", fmt.Errorf("%w: invalid seller pubkey: %v", ErrUnauthenticated, err)
+	}
+	if err := oraclesig.Verify(sellerPubKey, domain, app.txExecutor.ChainID(), payload, sig); err != nil {
+		return "", fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+	return sdk.AccAddress((&cosmossecp256k1.PubKey{Key: sellerPubKey.SerializeCompressed()}).Address()).String(), nil
+}
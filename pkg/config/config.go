@@ -0,0 +1,340 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/pelletier/go-toml"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// Config is the configuration of the oracle.
+// Values are loaded from the defaults, the config file, env vars and flags, in that order.
+type Config struct {
+	TendermintRPC string `toml:"tm_rpc" yaml:"tm_rpc"`
+	ChainID       string `toml:"chain_id" yaml:"chain_id"`
+
+	Operator    OperatorConfig    `toml:"operator" yaml:"operator"`
+	OracleKey   OracleKeyConfig   `toml:"oracle_key" yaml:"oracle_key"`
+	Join        JoinConfig        `toml:"join" yaml:"join"`
+	Tx          TxConfig          `toml:"tx" yaml:"tx"`
+	Attestation AttestationConfig `toml:"attestation" yaml:"attestation"`
+	API         APIConfig         `toml:"api" yaml:"api"`
+	DataCache   DataCacheConfig   `toml:"data_cache" yaml:"data_cache"`
+	Validation  ValidationConfig  `toml:"validation" yaml:"validation"`
+	Log         LogConfig         `toml:"log" yaml:"log"`
+}
+
+const (
+	OperatorSourceMnemonic = "mnemonic"
+	OperatorSourceEnv      = "env"
+	OperatorSourceFile     = "file"
+	OperatorSourcePrompt   = "prompt"
+	OperatorSourceKeyring  = "keyring"
+	OperatorSourceSealed   = "sealed"
+	OperatorSourceRemote   = "remote"
+)
+
+const (
+	// OnKeyMismatchRefuse refuses to run if the sealed oracle key doesn't match the oracle pubkey on chain.
+	OnKeyMismatchRefuse = "refuse"
+	// OnKeyMismatchObserve runs without the oracle key, so that nothing is signed or encrypted with the wrong key.
+	OnKeyMismatchObserve = "observe"
+)
+
+// MinDataTTL is the minimum data_cache.ttl, so that a submission is kept for longer than its signature is accepted
+// and cannot be replayed after it expires (see app.SubmissionWindow).
+const MinDataTTL = 10 * time.Minute
+
+type OperatorConfig struct {
+	// Source is where the operator key is loaded from.
+	Source string `toml:"source" yaml:"source"`
+	// Mnemonic is used if Source is "mnemonic". This exposes the mnemonic in plaintext, so it should be used only for testing.
+	Mnemonic string `toml:"mnemonic" yaml:"mnemonic"`
+	// MnemonicEnv is the env var containing the mnemonic if Source is "env".
+	MnemonicEnv string `toml:"mnemonic_env" yaml:"mnemonic_env"`
+	// MnemonicFile is the file containing the mnemonic if Source is "file". It must not be accessible by group or others.
+	MnemonicFile string `toml:"mnemonic_file" yaml:"mnemonic_file"`
+	// KeyringBackend, KeyringDir and KeyName specify the key in the Cosmos SDK keyring if Source is "keyring".
+	KeyringBackend string `toml:"keyring_backend" yaml:"keyring_backend"`
+	KeyringDir     string `toml:"keyring_dir" yaml:"keyring_dir"`
+	KeyName        string `toml:"key_name" yaml:"key_name"`
+	// SealedFile is where the operator key is sealed. It is read if Source is "sealed",
+	// and written when the key is imported from another source by the seal-operator-key command.
+	SealedFile string `toml:"sealed_file" yaml:"sealed_file"`
+	// RemoteSignerAddr is the addr of the remote signer (unix:///path/to/socket or tcp://host:port) if Source is "remote".
+	RemoteSignerAddr string `toml:"remote_signer_addr" yaml:"remote_signer_addr"`
+
+	// HDAccount, HDAddressIndex, HDCoinType and BIP39Passphrase specify how the key is derived
+	// if the source provides a mnemonic: m/44'/<coin type>'/<account>'/0/<address index>.
+	HDAccount       uint32 `toml:"hd_account" yaml:"hd_account"`
+	HDAddressIndex  uint32 `toml:"hd_address_index" yaml:"hd_address_index"`
+	HDCoinType      uint32 `toml:"hd_coin_type" yaml:"hd_coin_type"`
+	BIP39Passphrase string `toml:"bip39_passphrase" yaml:"bip39_passphrase"`
+}
+
+type OracleKeyConfig struct {
+	// File is where the oracle key is sealed.
+	File string `toml:"file" yaml:"file"`
+	// JoinStateFile is where an in-progress join is sealed, so that it can be resumed after a restart.
+	JoinStateFile string `toml:"join_state_file" yaml:"join_state_file"`
+	// OnMismatch is what to do if the sealed oracle key doesn't match the oracle pubkey on chain: "refuse" or "observe".
+	OnMismatch string `toml:"on_mismatch" yaml:"on_mismatch"`
+}
+
+type JoinConfig struct {
+	// Timeout is how long to wait for the join result. It waits forever if zero.
+	Timeout time.Duration `toml:"timeout" yaml:"timeout"`
+	// PollInterval is how often the status of the join is queried while waiting for its result.
+	PollInterval time.Duration `toml:"poll_interval" yaml:"poll_interval"`
+}
+
+type TxConfig struct {
+	GasLimit uint64 `toml:"gas_limit" yaml:"gas_limit"`
+	// Fees are the coins paid for each tx, such as "1000uhub".
+	Fees string `toml:"fees" yaml:"fees"`
+}
+
+// AttestationConfig tightens the policy that SGX reports of other oracles must satisfy.
+// The signer ID and the product ID are compiled into the binary (see pkg/sgx), and cannot be configured.
+type AttestationConfig struct {
+	// MinSecurityVersion raises the min security version above the built-in one. The built-in one is used if 0.
+	MinSecurityVersion uint `toml:"min_security_version" yaml:"min_security_version"`
+}
+
+type APIConfig struct {
+	// Addr is the listen addr of the HTTP API server. The server is disabled if empty.
+	Addr string `toml:"addr" yaml:"addr"`
+	// GRPCAddr is the listen addr of the gRPC server. The server is disabled if empty.
+	GRPCAddr string `toml:"grpc_addr" yaml:"grpc_addr"`
+	// AttestedTLS serves the API and gRPC over TLS with a certificate bound to the SGX report.
+	AttestedTLS bool `toml:"attested_tls" yaml:"attested_tls"`
+}
+
+type DataCacheConfig struct {
+	Dir                  string        `toml:"dir" yaml:"dir"`
+	MaxEntrySize         int           `toml:"max_entry_size" yaml:"max_entry_size"`
+	MaxSellerSize        int           `toml:"max_seller_size" yaml:"max_seller_size"`
+	MaxTotalSize         int           `toml:"max_total_size" yaml:"max_total_size"`
+	MaxSellersPerRequest int           `toml:"max_sellers_per_request" yaml:"max_sellers_per_request"`
+	TTL                  time.Duration `toml:"ttl" yaml:"ttl"`
+}
+
+type ValidationConfig struct {
+	// RulesFile is the JSON file of the validation.Rules applied to submitted data.
+	// Submitted data cannot be validated if empty.
+	RulesFile string `toml:"rules_file" yaml:"rules_file"`
+}
+
+type LogConfig struct {
+	Level  string `toml:"level" yaml:"level"`
+	Format string `toml:"format" yaml:"format"`
+}
+
+func Default() Config {
+	return Config{
+		TendermintRPC: "tcp://127.0.0.1:26657",
+		ChainID:       "dhub-1",
+		Operator: OperatorConfig{
+			Source:         OperatorSourceMnemonic,
+			MnemonicEnv:    "DORACLE_OPERATOR_MNEMONIC",
+			KeyringBackend: "file",
+			KeyringDir:     "/data/keyring",
+			SealedFile:     "/data/operator-key.sealed",
+			HDCoinType:     118,
+		},
+		OracleKey: OracleKeyConfig{
+			File:          "/data/oracle-key.sealed",
+			JoinStateFile: "/data/join-state.sealed",
+			OnMismatch:    OnKeyMismatchRefuse,
+		},
+		Join: JoinConfig{
+			Timeout:      30 * time.Minute,
+			PollInterval: 30 * time.Second,
+		},
+		Tx: TxConfig{
+			GasLimit: 500000,
+			Fees:     "0uhub",
+		},
+		DataCache: DataCacheConfig{
+			Dir:                  "/data/cache",
+			MaxEntrySize:         8 << 20,
+			MaxSellerSize:        64 << 20,
+			MaxTotalSize:         1 << 30,
+			MaxSellersPerRequest: 16,
+			TTL:                  24 * time.Hour,
+		},
+		Log: LogConfig{
+			Level:  "info",
+			Format: "text",
+		},
+	}
+}
+
+// LoadFile overwrites the config with the values in the TOML or YAML file, chosen by the file extension.
+func (c *Config) LoadFile(path string) error {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %w", path, err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		err = toml.NewDecoder(bytes.NewReader(bz)).Strict(true).Decode(c)
+	case ".yaml", ".yml":
+		err = yaml.UnmarshalStrict(bz, c)
+	default:
+		return fmt.Errorf("unsupported config file: %v (must be .toml, .yaml or .yml)", path)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to parse %v: %w", path, err)
+	}
+	return nil
+}
+
+// Validate checks the config on startup, so that misconfigurations are found before anything is sent to the chain.
+func (c Config) Validate() error {
+	if c.TendermintRPC == "" {
+		return fmt.Errorf("tm_rpc must be specified")
+	}
+	if c.ChainID == "" {
+		return fmt.Errorf("chain_id must be specified")
+	}
+	if err := c.Operator.Validate(); err != nil {
+		return err
+	}
+	if c.OracleKey.File == "" {
+		return fmt.Errorf("oracle_key.file must be specified")
+	}
+	if c.OracleKey.JoinStateFile == "" {
+		return fmt.Errorf("oracle_key.join_state_file must be specified")
+	}
+	if c.OracleKey.OnMismatch != OnKeyMismatchRefuse && c.OracleKey.OnMismatch != OnKeyMismatchObserve {
+		return fmt.Errorf("oracle_key.on_mismatch must be %v or %v", OnKeyMismatchRefuse, OnKeyMismatchObserve)
+	}
+	if c.Join.Timeout < 0 {
+		return fmt.Errorf("join.timeout must not be negative")
+	}
+	if c.Join.PollInterval <= 0 {
+		return fmt.Errorf("join.poll_interval must be positive")
+	}
+
+	if c.Tx.GasLimit == 0 {
+		return fmt.Errorf("tx.gas_limit must be positive")
+	}
+	if _, err := c.Tx.FeeCoins(); err != nil {
+		return err
+	}
+
+	for name, addr := range map[string]string{"api.addr": c.API.Addr, "api.grpc_addr": c.API.GRPCAddr} {
+		if addr == "" {
+			continue
+		}
+		if _, _, err := net.SplitHostPort(addr); err != nil {
+			return fmt.Errorf("invalid %v: %w", name, err)
+		}
+	}
+	if c.API.Addr != "" && c.API.Addr == c.API.GRPCAddr {
+		return fmt.Errorf("api.addr and api.grpc_addr must be different")
+	}
+
+	if c.DataCache.Dir == "" {
+		return fmt.Errorf("data_cache.dir must be specified")
+	}
+	if c.DataCache.MaxEntrySize <= 0 || c.DataCache.MaxTotalSize < c.DataCache.MaxEntrySize {
+		return fmt.Errorf("data_cache.max_entry_size must be positive and <= data_cache.max_total_size")
+	}
+	if c.DataCache.MaxSellerSize < c.DataCache.MaxEntrySize || c.DataCache.MaxSellerSize > c.DataCache.MaxTotalSize {
+		return fmt.Errorf("data_cache.max_seller_size must be between data_cache.max_entry_size and data_cache.max_total_size")
+	}
+	if c.DataCache.MaxSellersPerRequest <= 0 {
+		return fmt.Errorf("data_cache.max_sellers_per_request must be positive")
+	}
+	if c.DataCache.TTL < MinDataTTL {
+		return fmt.Errorf("data_cache.ttl must be at least %v", MinDataTTL)
+	}
+
+	if _, err := log.ParseLevel(c.Log.Level); err != nil {
+		return fmt.Errorf("invalid log.level: %w", err)
+	}
+	if c.Log.Format != "text" && c.Log.Format != "json" {
+		return fmt.Errorf("log.format must be text or json")
+	}
+
+	return nil
+}
+
+func (c OperatorConfig) Validate() error {
+	// hardened indexes must be < 2^31
+	for name, v := range map[string]uint32{"hd_account": c.HDAccount, "hd_coin_type": c.HDCoinType} {
+		if v >= 1<<31 {
+			return fmt.Errorf("operator.%v must be < %v", name, uint32(1<<31))
+		}
+	}
+
+	switch c.Source {
+	case OperatorSourceMnemonic:
+		if c.Mnemonic == "" {
+			return fmt.Errorf("operator.mnemonic must be specified for the operator key source %v", c.Source)
+		}
+	case OperatorSourceEnv:
+		if c.MnemonicEnv == "" {
+			return fmt.Errorf("operator.mnemonic_env must be specified for the operator key source %v", c.Source)
+		}
+	case OperatorSourceFile:
+		if c.MnemonicFile == "" {
+			return fmt.Errorf("operator.mnemonic_file must be specified for the operator key source %v", c.Source)
+		}
+	case OperatorSourcePrompt:
+	case OperatorSourceKeyring:
+		switch c.KeyringBackend {
+		case keyring.BackendFile, keyring.BackendTest, keyring.BackendOS:
+		default:
+			return fmt.Errorf("operator.keyring_backend must be file, test or os")
+		}
+		if c.KeyName == "" {
+			return fmt.Errorf("operator.key_name must be specified for the operator key source %v", c.Source)
+		}
+	case OperatorSourceSealed:
+		if c.SealedFile == "" {
+			return fmt.Errorf("operator.sealed_file must be specified for the operator key source %v", c.Source)
+		}
+	case OperatorSourceRemote:
+		if c.RemoteSignerAddr == "" {
+			return fmt.Errorf("operator.remote_signer_addr must be specified for the operator key source %v", c.Source)
+		}
+	default:
+		return fmt.Errorf("operator.source must be one of mnemonic, env, file, prompt, keyring, sealed and remote")
+	}
+	return nil
+}
+
+func (c TxConfig) FeeCoins() (sdk.Coins, error) {
+	fees, err := sdk.ParseCoinsNormalized(c.Fees)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tx.fees: %w", err)
+	}
+	return fees, nil
+}
+
+// ApplyLog applies the log settings to the global logger.
+func (c LogConfig) ApplyLog() error {
+	level, err := log.ParseLevel(c.Level)
+	if err != nil {
+		return fmt.Errorf("invalid log level: %w", err)
+	}
+	log.SetLevel(level)
+
+	if c.Format == "json" {
+		log.SetFormatter(&log.JSONFormatter{})
+	}
+	return nil
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of env vars overriding the config. The env var of a flag is derived from the flag name:
+// e.g. DORACLE_CHAIN_ID for -chain-id.
+const EnvPrefix = "DORACLE_"
+
+// RegisterFlags registers the flags overriding the config to fs, and binds them to c.
+// The -config flag is registered as well, which specifies the config file.
+func (c *Config) RegisterFlags(fs *flag.FlagSet) *string {
+	fs.StringVar(&c.TendermintRPC, "tm-rpc", c.TendermintRPC, "tendermint rpc addr")
+	fs.StringVar(&c.ChainID, "chain-id", c.ChainID, "chain ID")
+	fs.StringVar(&c.Operator.Source, "operator-source", c.Operator.Source, "where the operator key is loaded from (mnemonic, env, file, prompt, keyring, sealed or remote)")
+	fs.StringVar(&c.Operator.Mnemonic, "operator", c.Operator.Mnemonic, "operator mnemonic (exposed in plaintext, only for testing)")
+	fs.StringVar(&c.Operator.MnemonicEnv, "operator-mnemonic-env", c.Operator.MnemonicEnv, "env var containing the operator mnemonic")
+	fs.StringVar(&c.Operator.MnemonicFile, "operator-mnemonic-file", c.Operator.MnemonicFile, "file containing the operator mnemonic, which must not be accessible by group or others")
+	fs.StringVar(&c.Operator.KeyringBackend, "keyring-backend", c.Operator.KeyringBackend, "keyring backend (file, test or os)")
+	fs.StringVar(&c.Operator.KeyringDir, "keyring-dir", c.Operator.KeyringDir, "keyring directory")
+	fs.StringVar(&c.Operator.KeyName, "key-name", c.Operator.KeyName, "name of the operator key in the keyring")
+	fs.StringVar(&c.Operator.RemoteSignerAddr, "remote-signer-addr", c.Operator.RemoteSignerAddr, "addr of the remote signer (unix:///path/to/socket or tcp://host:port)")
+	uint32Var(fs, &c.Operator.HDAccount, "hd-account", "BIP44 account of the operator key derived from a mnemonic")
+	uint32Var(fs, &c.Operator.HDAddressIndex, "hd-address-index", "BIP44 address index of the operator key derived from a mnemonic")
+	uint32Var(fs, &c.Operator.HDCoinType, "hd-coin-type", "BIP44 coin type of the operator key derived from a mnemonic")
+	fs.StringVar(&c.Operator.BIP39Passphrase, "bip39-passphrase", c.Operator.BIP39Passphrase, "BIP39 passphrase of the operator mnemonic (prefer the env var)")
+	fs.StringVar(&c.Operator.SealedFile, "operator-sealed-file", c.Operator.SealedFile, "file where the operator key is sealed")
+	fs.StringVar(&c.OracleKey.File, "oracle-key-file", c.OracleKey.File, "file where the oracle key is sealed")
+	fs.StringVar(&c.OracleKey.JoinStateFile, "join-state-file", c.OracleKey.JoinStateFile, "file where an in-progress join is sealed")
+	fs.StringVar(&c.OracleKey.OnMismatch, "oracle-key-on-mismatch", c.OracleKey.OnMismatch, "what to do if the oracle key doesn't match the one on chain (refuse or observe)")
+	fs.DurationVar(&c.Join.Timeout, "join-timeout", c.Join.Timeout, "how long to wait for the join result (forever if 0)")
+	fs.DurationVar(&c.Join.PollInterval, "join-poll-interval", c.Join.PollInterval, "how often the join status is queried while waiting for the join result")
+	fs.Uint64Var(&c.Tx.GasLimit, "gas-limit", c.Tx.GasLimit, "gas limit of each tx")
+	fs.StringVar(&c.Tx.Fees, "fees", c.Tx.Fees, "fees paid for each tx (e.g. 1000uhub)")
+	fs.UintVar(&c.Attestation.MinSecurityVersion, "attestation-min-security-version", c.Attestation.MinSecurityVersion, "min security version that SGX reports of other oracles must have, which can only raise the built-in one (built-in if 0)")
+	fs.StringVar(&c.API.Addr, "api-addr", c.API.Addr, "listen addr of the API server (disabled if empty)")
+	fs.StringVar(&c.API.GRPCAddr, "grpc-addr", c.API.GRPCAddr, "listen addr of the gRPC server (disabled if empty)")
+	fs.BoolVar(&c.API.AttestedTLS, "api-tls", c.API.AttestedTLS, "serve the API and gRPC over TLS with a certificate bound to the SGX report")
+	fs.StringVar(&c.DataCache.Dir, "data-cache-dir", c.DataCache.Dir, "directory where data submitted directly by sellers is kept")
+	fs.IntVar(&c.DataCache.MaxEntrySize, "data-max-size", c.DataCache.MaxEntrySize, "max size in bytes of data submitted directly by a seller")
+	fs.IntVar(&c.DataCache.MaxSellerSize, "data-seller-size", c.DataCache.MaxSellerSize, "max total size in bytes of data submitted directly by a seller across requests")
+	fs.IntVar(&c.DataCache.MaxSellersPerRequest, "data-max-sellers", c.DataCache.MaxSellersPerRequest, "max number of sellers who can submit data directly for a request")
+	fs.IntVar(&c.DataCache.MaxTotalSize, "data-cache-size", c.DataCache.MaxTotalSize, "max total size in bytes of data submitted directly by sellers")
+	fs.DurationVar(&c.DataCache.TTL, "data-ttl", c.DataCache.TTL, "how long data submitted directly by sellers is kept")
+	fs.StringVar(&c.Validation.RulesFile, "validation-rules", c.Validation.RulesFile, "JSON file of the rules applied to validate submitted data (disabled if empty)")
+	fs.StringVar(&c.Log.Level, "log-level", c.Log.Level, "log level (debug, info, warn, error)")
+	fs.StringVar(&c.Log.Format, "log-format", c.Log.Format, "log format (text or json)")
+
+	return fs.String("config", os.Getenv(EnvPrefix+"CONFIG"), "path of the TOML or YAML config file")
+}
+
+// Load parses args into fs whose flags were registered by RegisterFlags on c,
+// and loads the config in the order of the defaults, the config file, env vars and flags.
+// The loaded config is validated.
+func (c *Config) Load(fs *flag.FlagSet, configPath *string, args []string) error {
+	// parse once to find the config file
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	*c = Default()
+	if *configPath != "" {
+		if err := c.LoadFile(*configPath); err != nil {
+			return err
+		}
+	}
+
+	// only the flags registered by RegisterFlags are overridden by env vars, not the flags of commands like -force
+	configFlags := flag.NewFlagSet("config", flag.ContinueOnError)
+	new(Config).RegisterFlags(configFlags)
+
+	var envErr error
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || configFlags.Lookup(f.Name) == nil || envErr != nil {
+			return
+		}
+		if value, ok := os.LookupEnv(EnvName(f.Name)); ok {
+			if err := f.Value.Set(value); err != nil {
+				envErr = fmt.Errorf("invalid %v: %w", EnvName(f.Name), err)
+			}
+		}
+	})
+	if envErr != nil {
+		return envErr
+	}
+
+	// parse again so that flags take precedence over the config file and env vars
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
+
+func EnvName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+type uint32Value uint32
+
+func uint32Var(fs *flag.FlagSet, p *uint32, name, usage string) {
+	fs.Var((*uint32Value)(p), name, usage)
+}
+
+func (v *uint32Value) Set(s string) error {
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return err
+	}
+	*v = uint32Value(n)
+	return nil
+}
+
+func (v *uint32Value) String() string {
+	return strconv.FormatUint(uint64(*v), 10)
+}
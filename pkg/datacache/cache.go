@@ -0,0 +1,249 @@
+package datacache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+)
+
+var (
+	ErrNotFound  = errors.New("data not found")
+	ErrExists    = errors.New("data already submitted")
+	ErrTooLarge  = errors.New("data too large")
+	ErrCacheFull = errors.New("cache full")
+	ErrQuota     = errors.New("quota exceeded")
+)
+
+// Cache keeps ciphertexts submitted directly by sellers until the TTL passes.
+// Entries are keyed by the request ID and the seller, so that a seller cannot take the request ID of another seller.
+// Entries are sealed in the enclave before being written to disk.
+//
+// Neither the request ID nor the seller can be checked against the chain yet, so anyone can submit data with a new key.
+// The size per seller and the number of sellers per request are limited, so that a few keys cannot fill the cache
+// or a request alone.
+type Cache struct {
+	dir                  string
+	maxEntrySize         int
+	maxSellerSize        int
+	maxTotalSize         int
+	maxSellersPerRequest int
+	ttl                  time.Duration
+
+	mtx            sync.Mutex
+	index          map[entryKey]entryMeta
+	totalSize      int
+	sellerSizes    map[string]int
+	requestSellers map[string]int
+}
+
+type entryKey struct {
+	requestID string
+	seller    string
+}
+
+type entryMeta struct {
+	size      int
+	expiresAt time.Time
+}
+
+type entry struct {
+	RequestID  string    `json:"request_id"`
+	Seller     string    `json:"seller"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+// New creates a cache on cfg.Dir, loading entries which were stored before the restart.
+func New(cfg config.DataCacheConfig) (*Cache, error) {
+	if err := os.MkdirAll(cfg.Dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create %v: %w", cfg.Dir, err)
+	}
+
+	c := &Cache{
+		dir:                  cfg.Dir,
+		maxEntrySize:         cfg.MaxEntrySize,
+		maxSellerSize:        cfg.MaxSellerSize,
+		maxTotalSize:         cfg.MaxTotalSize,
+		maxSellersPerRequest: cfg.MaxSellersPerRequest,
+		ttl:                  cfg.TTL,
+		index:                make(map[entryKey]entryMeta),
+		sellerSizes:          make(map[string]int),
+		requestSellers:       make(map[string]int),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *Cache) MaxEntrySize() int {
+	return c.maxEntrySize
+}
+
+// Put stores the ciphertext submitted by the seller for the request ID, and returns when it expires.
+func (c *Cache) Put(requestID, seller string, ciphertext []byte) (time.Time, error) {
+	if len(ciphertext) > c.maxEntrySize {
+		return time.Time{}, fmt.Errorf("%w: %v > %v", ErrTooLarge, len(ciphertext), c.maxEntrySize)
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.pruneExpired()
+
+	key := entryKey{requestID: requestID, seller: seller}
+	if _, ok := c.index[key]; ok {
+		return time.Time{}, fmt.Errorf("%w: %v by %v", ErrExists, requestID, seller)
+	}
+	if size := c.sellerSizes[seller] + len(ciphertext); size > c.maxSellerSize {
+		return time.Time{}, fmt.Errorf("%w: %v would keep %v bytes > %v", ErrQuota, seller, size, c.maxSellerSize)
+	}
+	if c.requestSellers[requestID] >= c.maxSellersPerRequest {
+		return time.Time{}, fmt.Errorf("%w: request %v already has %v sellers", ErrQuota, requestID, c.maxSellersPerRequest)
+	}
+	if c.totalSize+len(ciphertext) > c.maxTotalSize {
+		return time.Time{}, ErrCacheFull
+	}
+
+	e := entry{
+		RequestID:  requestID,
+		Seller:     seller,
+		ExpiresAt:  time.Now().Add(c.ttl),
+		Ciphertext: ciphertext,
+	}
+	if err := c.write(e); err != nil {
+		return time.Time{}, err
+	}
+
+	c.add(key, entryMeta{size: len(ciphertext), expiresAt: e.ExpiresAt})
+	return e.ExpiresAt, nil
+}
+
+// Get returns the ciphertext submitted by the seller for the request ID.
+func (c *Cache) Get(requestID, seller string) ([]byte, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.pruneExpired()
+
+	key := entryKey{requestID: requestID, seller: seller}
+	if _, ok := c.index[key]; !ok {
+		return nil, fmt.Errorf("%w: %v by %v", ErrNotFound, requestID, seller)
+	}
+
+	e, err := c.read(c.path(key))
+	if err != nil {
+		return nil, err
+	}
+	return e.Ciphertext, nil
+}
+
+func (c *Cache) load() error {
+	files, err := ioutil.ReadDir(c.dir)
+	if err != nil {
+		return fmt.Errorf("failed to read %v: %w", c.dir, err)
+	}
+
+	for _, file := range files {
+		path := filepath.Join(c.dir, file.Name())
+
+		e, err := c.read(path)
+		if err != nil || time.Now().After(e.ExpiresAt) {
+			if err != nil {
+				log.Warnf("removing unreadable cache entry %v: %v", path, err)
+			}
+			os.Remove(path)
+			continue
+		}
+
+		c.add(entryKey{requestID: e.RequestID, seller: e.Seller}, entryMeta{size: len(e.Ciphertext), expiresAt: e.ExpiresAt})
+	}
+
+	log.Infof("%v data cache entries loaded", len(c.index))
+	return nil
+}
+
+func (c *Cache) pruneExpired() {
+	now := time.Now()
+	for key, meta := range c.index {
+		if now.After(meta.expiresAt) {
+			log.Infof("data for request %v by %v expired", key.requestID, key.seller)
+			c.remove(key)
+		}
+	}
+}
+
+func (c *Cache) add(key entryKey, meta entryMeta) {
+	c.index[key] = meta
+	c.totalSize += meta.size
+	c.sellerSizes[key.seller] += meta.size
+	c.requestSellers[key.requestID]++
+}
+
+func (c *Cache) remove(key entryKey) {
+	if err := os.Remove(c.path(key)); err != nil && !os.IsNotExist(err) {
+		log.Errorf("failed to remove cache entry of %v by %v: %v", key.requestID, key.seller, err)
+	}
+	size := c.index[key].size
+	c.totalSize -= size
+	c.sellerSizes[key.seller] -= size
+	if c.sellerSizes[key.seller] == 0 {
+		delete(c.sellerSizes, key.seller)
+	}
+	c.requestSellers[key.requestID]--
+	if c.requestSellers[key.requestID] == 0 {
+		delete(c.requestSellers, key.requestID)
+	}
+	delete(c.index, key)
+}
+
+func (c *Cache) write(e entry) error {
+	bz, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("failed to marshal entry: %w", err)
+	}
+	sealed, err := sgx.Seal(bz)
+	if err != nil {
+		return fmt.Errorf("failed to seal entry: %w", err)
+	}
+
+	path := c.path(entryKey{requestID: e.RequestID, seller: e.Seller})
+	if err := ioutil.WriteFile(path, sealed, 0600); err != nil {
+		return fmt.Errorf("failed to write %v: %w", path, err)
+	}
+	return nil
+}
+
+func (c *Cache) read(path string) (entry, error) {
+	sealed, err := ioutil.ReadFile(path)
+	if err != nil {
+		return entry{}, fmt.Errorf("failed to read %v: %w", path, err)
+	}
+	bz, err := sgx.Unseal(sealed)
+	if err != nil {
+		return entry{}, fmt.Errorf("failed to unseal %v: %w", path, err)
+	}
+
+	var e entry
+	if err := json.Unmarshal(bz, &e); err != nil {
+		return entry{}, fmt.Errorf("failed to unmarshal %v: %w", path, err)
+	}
+	return e, nil
+}
+
+// path doesn't use the request ID and the seller as they are, so that they cannot escape the cache directory.
+func (c *Cache) path(key entryKey) string {
+	hash := sha256.Sum256([]byte(key.requestID + "\x00" + key.seller))
+	return filepath.Join(c.dir, hex.EncodeToString(hash[:])+".sealed")
+}
@@ -0,0 +1,151 @@
+package event
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/btcsuite/btcd/btcec"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+)
+
+// ErrInvalidEvent is returned if an event doesn't have the attributes expected, so that a malformed event
+// is rejected as an error instead of crashing the node.
+var ErrInvalidEvent = errors.New("invalid event")
+
+// JoinEventData is a join event emitted by MsgJoin.
+type JoinEventData struct {
+	ID              uint64
+	OperatorAddress string
+	EnclaveReport   []byte
+	EncPubKeyBytes  []byte
+	EncPubKey       *btcec.PublicKey
+	// Err is set if the join has a valid ID but other attributes are malformed, so that it can be voted no
+	// instead of being left pending.
+	Err error
+}
+
+// JoinResultData is a join_result event emitted when the voting on a join is closed.
+type JoinResultData struct {
+	ID     uint64
+	Status oracletypes.JoinStatus
+	// Value is the oracle key encrypted to the joiner in base64, if approved.
+	Value string
+}
+
+// DecodeJoinEvents decodes all join events in the result, in the order they were emitted.
+// A tx may contain multiple MsgJoin, so each attribute may have multiple values.
+// Each join is decoded independently: a join with malformed attributes is returned with Err set.
+// An error is returned if the attributes cannot be paired up, or if the ID of a join is invalid,
+// but the joins which could be decoded are returned even then.
+func DecodeJoinEvents(event ctypes.ResultEvent) ([]JoinEventData, error) {
+	attrs, n, err := eventAttributes(event, oracletypes.EventTypeJoin,
+		oracletypes.AttributeKeyID,
+		oracletypes.AttributeKeyOperatorAddress,
+		oracletypes.AttributeKeyEnclaveReportBase64,
+		oracletypes.AttributeKeyEncPubKeyBase64,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	joins := make([]JoinEventData, 0, n)
+	var invalidIDs []string
+	for i := 0; i < n; i++ {
+		id, err := strconv.ParseUint(attrs[oracletypes.AttributeKeyID][i], 10, 64)
+		if err != nil {
+			invalidIDs = append(invalidIDs, strconv.Quote(attrs[oracletypes.AttributeKeyID][i]))
+			continue
+		}
+
+		join := JoinEventData{
+			ID:              id,
+			OperatorAddress: attrs[oracletypes.AttributeKeyOperatorAddress][i],
+		}
+		join.EnclaveReport, join.EncPubKeyBytes, join.EncPubKey, join.Err = decodeJoinAttributes(
+			attrs[oracletypes.AttributeKeyEnclaveReportBase64][i],
+			attrs[oracletypes.AttributeKeyEncPubKeyBase64][i],
+		)
+		joins = append(joins, join)
+	}
+
+	if len(invalidIDs) > 0 {
+		return joins, fmt.Errorf("%w: invalid join.id: %v", ErrInvalidEvent, strings.Join(invalidIDs, ", "))
+	}
+	return joins, nil
+}
+
+func decodeJoinAttributes(enclaveReportBase64, encPubKeyBase64 string) ([]byte, []byte, *btcec.PublicKey, error) {
+	enclaveReport, err := base64.StdEncoding.DecodeString(enclaveReportBase64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: join.enclave_report_base64: %v", ErrInvalidEvent, err)
+	}
+
+	encPubKeyBytes, err := base64.StdEncoding.DecodeString(encPubKeyBase64)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: join.enc_pub_key_base64: %v", ErrInvalidEvent, err)
+	}
+	encPubKey, err := secp256k1.PubKeyFromBytes(encPubKeyBytes)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("%w: encryption public key: %v", ErrInvalidEvent, err)
+	}
+
+	return enclaveReport, encPubKeyBytes, encPubKey, nil
+}
+
+// DecodeJoinResults decodes all join_result events in the result.
+// Votings on multiple joins may be closed in the same block.
+func DecodeJoinResults(event ctypes.ResultEvent) ([]JoinResultData, error) {
+	attrs, n, err := eventAttributes(event, oracletypes.EventTypeJoinResult,
+		oracletypes.AttributeKeyID,
+		oracletypes.AttributeKeyStatus,
+		oracletypes.AttributeKeyValue,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]JoinResultData, 0, n)
+	for i := 0; i < n; i++ {
+		id, err := strconv.ParseUint(attrs[oracletypes.AttributeKeyID][i], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: join_result.id: %v", ErrInvalidEvent, err)
+		}
+
+		statusValue, ok := oracletypes.JoinStatus_value[attrs[oracletypes.AttributeKeyStatus][i]]
+		if !ok {
+			return nil, fmt.Errorf("%w: join_result.status of join %v: %q", ErrInvalidEvent, id, attrs[oracletypes.AttributeKeyStatus][i])
+		}
+
+		results = append(results, JoinResultData{
+			ID:     id,
+			Status: oracletypes.JoinStatus(statusValue),
+			Value:  attrs[oracletypes.AttributeKeyValue][i],
+		})
+	}
+	return results, nil
+}
+
+// eventAttributes returns the values of the attributes of the event type, and the number of the event instances.
+// Every attribute must have the same number of values, so that the i-th values belong to the same instance.
+func eventAttributes(event ctypes.ResultEvent, eventType string, keys ...string) (map[string][]string, int, error) {
+	attrs := make(map[string][]string, len(keys))
+	n := -1
+	for _, key := range keys {
+		compositeKey := eventType + "." + key
+		values := event.Events[compositeKey]
+		if len(values) == 0 {
+			return nil, 0, fmt.Errorf("%w: %v not found", ErrInvalidEvent, compositeKey)
+		}
+		if n >= 0 && len(values) != n {
+			return nil, 0, fmt.Errorf("%w: %v has %v values, but %v expected", ErrInvalidEvent, compositeKey, len(values), n)
+		}
+		n = len(values)
+		attrs[key] = values
+	}
+	return attrs, n, nil
+}
@@ -0,0 +1,125 @@
+package event
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+)
+
+// testJoinAttrs are the attributes of a join event instance.
+type testJoinAttrs struct {
+	id, operator, report, encPubKey string
+}
+
+func newTestJoinAttrs(t *testing.T, id string) testJoinAttrs {
+	t.Helper()
+
+	encPrivKey, err := secp256k1.NewPrivKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testJoinAttrs{
+		id:        id,
+		operator:  "operator-" + id,
+		report:    base64.StdEncoding.EncodeToString([]byte("report-" + id)),
+		encPubKey: base64.StdEncoding.EncodeToString(encPrivKey.PubKey().SerializeCompressed()),
+	}
+}
+
+// joinTxEvent synthesizes the result of a tx containing a MsgJoin for each of joins.
+func joinTxEvent(joins ...testJoinAttrs) ctypes.ResultEvent {
+	events := make(map[string][]string)
+	for _, join := range joins {
+		for key, value := range map[string]string{
+			oracletypes.AttributeKeyID:                  join.id,
+			oracletypes.AttributeKeyOperatorAddress:     join.operator,
+			oracletypes.AttributeKeyEnclaveReportBase64: join.report,
+			oracletypes.AttributeKeyEncPubKeyBase64:     join.encPubKey,
+		} {
+			compositeKey := oracletypes.EventTypeJoin + "." + key
+			events[compositeKey] = append(events[compositeKey], value)
+		}
+	}
+	return ctypes.ResultEvent{Events: events}
+}
+
+func TestDecodeJoinEvents(t *testing.T) {
+	attrs := []testJoinAttrs{newTestJoinAttrs(t, "1"), newTestJoinAttrs(t, "2"), newTestJoinAttrs(t, "3")}
+
+	joins, err := DecodeJoinEvents(joinTxEvent(attrs...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joins) != len(attrs) {
+		t.Fatalf("expected %v joins, got %v", len(attrs), len(joins))
+	}
+	for i, join := range joins {
+		if join.ID != uint64(i+1) || join.OperatorAddress != attrs[i].operator {
+			t.Errorf("join %v: unexpected id or operator: %v, %v", i, join.ID, join.OperatorAddress)
+		}
+		if !bytes.Equal(join.EnclaveReport, []byte("report-"+attrs[i].id)) {
+			t.Errorf("join %v: unexpected report: %q", i, join.EnclaveReport)
+		}
+		if join.EncPubKey == nil || join.Err != nil {
+			t.Errorf("join %v: unexpected enc pubkey or error: %v, %v", i, join.EncPubKey, join.Err)
+		}
+	}
+}
+
+func TestDecodeJoinEventsMissingKey(t *testing.T) {
+	event := joinTxEvent(newTestJoinAttrs(t, "1"))
+	delete(event.Events, oracletypes.EventTypeJoin+"."+oracletypes.AttributeKeyEncPubKeyBase64)
+
+	if _, err := DecodeJoinEvents(event); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent, got %v", err)
+	}
+}
+
+func TestDecodeJoinEventsMismatchedCount(t *testing.T) {
+	event := joinTxEvent(newTestJoinAttrs(t, "1"), newTestJoinAttrs(t, "2"))
+	key := oracletypes.EventTypeJoin + "." + oracletypes.AttributeKeyEnclaveReportBase64
+	event.Events[key] = event.Events[key][:1]
+
+	if _, err := DecodeJoinEvents(event); !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent, got %v", err)
+	}
+}
+
+func TestDecodeJoinEventsBadBase64(t *testing.T) {
+	badReport := newTestJoinAttrs(t, "2")
+	badReport.report = "not base64!"
+	badPubKey := newTestJoinAttrs(t, "3")
+	badPubKey.encPubKey = base64.StdEncoding.EncodeToString([]byte("not a pubkey"))
+
+	joins, err := DecodeJoinEvents(joinTxEvent(newTestJoinAttrs(t, "1"), badReport, badPubKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joins) != 3 {
+		t.Fatalf("expected 3 joins, got %v", len(joins))
+	}
+	if joins[0].Err != nil {
+		t.Errorf("join 1: unexpected error: %v", joins[0].Err)
+	}
+	for _, join := range joins[1:] {
+		if !errors.Is(join.Err, ErrInvalidEvent) {
+			t.Errorf("join %v: expected ErrInvalidEvent, got %v", join.ID, join.Err)
+		}
+	}
+}
+
+func TestDecodeJoinEventsInvalidID(t *testing.T) {
+	joins, err := DecodeJoinEvents(joinTxEvent(newTestJoinAttrs(t, "1"), newTestJoinAttrs(t, "x"), newTestJoinAttrs(t, "3")))
+	if !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent, got %v", err)
+	}
+	// the others are still decoded
+	if len(joins) != 2 || joins[0].ID != 1 || joins[1].ID != 3 {
+		t.Fatalf("unexpected joins: %+v", joins)
+	}
+}
@@ -11,19 +11,24 @@ import (
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
 	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/tx"
-	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/envelope"
 	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
 )
 
 type JoinEvent struct {
 	oraclePrivKey *btcec.PrivateKey
-	txExecutor    tx.Executor
+	voter         joinVoter
+}
+
+// joinVoter casts votes on joins, which is tx.Executor except in tests.
+type joinVoter interface {
+	VoteForJoin(joinID uint64, option oracletypes.VoteOption, yesValue, reason string) error
 }
 
 func NewJoinEvent(oraclePrivKey *btcec.PrivateKey, txExecutor tx.Executor) JoinEvent {
 	return JoinEvent{
 		oraclePrivKey: oraclePrivKey,
-		txExecutor:    txExecutor,
+		voter:         txExecutor,
 	}
 }
 
@@ -35,49 +40,72 @@ func (e JoinEvent) Query() string {
 	return "tm.event='Tx' AND message.module='oracle' AND message.action='join'"
 }
 
+func (e JoinEvent) TxQuery() string {
+	return "message.module='oracle' AND message.action='join'"
+}
+
 func (e JoinEvent) Handler(event ctypes.ResultEvent) error {
 	log.Debugf("JOIN EVENT: %v", event)
 
-	joinID, err := strconv.ParseUint(event.Events["join.id"][0], 10, 64)
-	if err != nil {
-		return fmt.Errorf("failed to parse join.id: %w", err)
+	// joins which could be decoded are handled even if others couldn't
+	joins, decodeErr := DecodeJoinEvents(event)
+	if decodeErr != nil {
+		log.Errorf("failed to decode join event: %v", decodeErr)
 	}
 
-	enclaveReportBase64 := event.Events["join.enclave_report_base64"][0]
-	enclaveReport, err := base64.StdEncoding.DecodeString(enclaveReportBase64)
-	if err != nil {
-		return fmt.Errorf("failed to decode join.enclave_report_base64: %w", err)
+	// a tx may contain multiple joins. each is voted on independently, so that a failure doesn't block the others.
+	failed := 0
+	for _, join := range joins {
+		if err := e.handleJoin(join); err != nil {
+			log.Errorf("failed to handle join %v: %v", join.ID, err)
+			failed++
+		}
 	}
-
-	encPubKeyBase64 := event.Events["join.enc_pub_key_base64"][0]
-	encPubKeyBytes, err := base64.StdEncoding.DecodeString(encPubKeyBase64)
-	if err != nil {
-		return fmt.Errorf("failed to decode join.enc_pub_key_base64: %w", err)
+	if failed > 0 {
+		return fmt.Errorf("failed to handle %v of %v joins", failed, len(joins))
 	}
-	encPubkey, err := secp256k1.PubKeyFromBytes(encPubKeyBytes)
-	if err != nil {
-		return fmt.Errorf("invalid encryption public key: %w", err)
+	if decodeErr != nil {
+		return fmt.Errorf("failed to decode join event: %w", decodeErr)
 	}
+	return nil
+}
 
+// handleJoin verifies the SGX report of the joiner, and votes for the join.
+// If the report is valid, the oracle key is encrypted to the joiner in the vote.
+// A malformed join is voted no, so that it doesn't stay pending.
+func (e JoinEvent) handleJoin(join JoinEventData) error {
 	voteOption := oracletypes.OptionYes
-	encPubKeyHash := sha256.Sum256(encPubKeyBytes)
-	if err := sgx.VerifyRemoteReport(enclaveReport, encPubKeyHash[:]); err != nil {
-		log.Infof("SGX report verification failed: %v", err)
+	reason := ""
+	if join.Err != nil {
+		log.Infof("join %v is malformed: %v", join.ID, join.Err)
 		voteOption = oracletypes.OptionNo
+		reason = join.Err.Error()
+	} else {
+		encPubKeyHash := sha256.Sum256(join.EncPubKeyBytes)
+		if err := sgx.VerifyRemoteReport(join.EnclaveReport, encPubKeyHash[:]); err != nil {
+			log.Infof("SGX report verification of join %v failed: %v", join.ID, err)
+			voteOption = oracletypes.OptionNo
+			reason = err.Error()
+		}
 	}
 
 	yesValue := ""
 	if voteOption == oracletypes.OptionYes {
-		encryptedOraclePrivKey, err := secp256k1.Encrypt(encPubkey, e.oraclePrivKey.Serialize())
+		encryptedOraclePrivKey, err := envelope.SealECIES(join.EncPubKey, 0, joinIDAssociatedData(join.ID), e.oraclePrivKey.Serialize())
 		if err != nil {
 			return fmt.Errorf("failed to encrypt oracle priv key: %w", err)
 		}
 		yesValue = base64.StdEncoding.EncodeToString(encryptedOraclePrivKey)
 	}
 
-	if err := e.txExecutor.VoteForJoin(joinID, voteOption, yesValue); err != nil {
+	if err := e.voter.VoteForJoin(join.ID, voteOption, yesValue, reason); err != nil {
 		return fmt.Errorf("failed to vote for join: %w", err)
 	}
 
 	return nil
 }
+
+// joinIDAssociatedData binds the encrypted oracle key to the join, so that it cannot be replayed to another join.
+func joinIDAssociatedData(joinID uint64) []byte {
+	return []byte(strconv.FormatUint(joinID, 10))
+}
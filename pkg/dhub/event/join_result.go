@@ -1,16 +1,21 @@
 package event
 
 import (
+	"bytes"
 	"encoding/base64"
+	"errors"
 	"fmt"
 
 	"github.com/btcsuite/btcd/btcec"
 	ctypes "github.com/tendermint/tendermint/rpc/core/types"
 	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
-	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/envelope"
 	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
 )
 
+// ErrJoinRejected is returned if the join was not approved by the oracles.
+var ErrJoinRejected = errors.New("join not approved")
+
 type JoinResultEvent struct {
 	joinID            uint64
 	encPrivKey        *btcec.PrivateKey
@@ -34,22 +39,43 @@ func (e JoinResultEvent) Query() string {
 }
 
 func (e JoinResultEvent) Handler(event ctypes.ResultEvent) error {
-	status := oracletypes.JoinStatus(
-		oracletypes.JoinStatus_value[event.Events["join_result.status"][0]],
-	)
+	results, err := DecodeJoinResults(event)
+	if err != nil {
+		return fmt.Errorf("failed to decode join_result event: %w", err)
+	}
+
+	// the event may contain the results of other joins closed in the same block
+	for _, result := range results {
+		if result.ID == e.joinID {
+			return e.Apply(result.Status, result.Value)
+		}
+	}
+	return fmt.Errorf("%w: join_result of join %v not found", ErrInvalidEvent, e.joinID)
+}
+
+// Apply handles the result of the join, which is either from the join_result event or from the join queried on chain.
+// If approved, the oracle key encrypted to this node is decrypted and sealed to the file.
+func (e JoinResultEvent) Apply(status oracletypes.JoinStatus, encryptedOraclePrivKeyBase64 string) error {
 	if status != oracletypes.JOIN_STATUS_APPROVED {
-		return fmt.Errorf("join status not approved: %v", status.String())
+		return fmt.Errorf("%w: %v", ErrJoinRejected, status.String())
 	}
 
-	encryptedOraclePrivKey, err := base64.StdEncoding.DecodeString(event.Events["join_result.value"][0])
+	encryptedOraclePrivKey, err := base64.StdEncoding.DecodeString(encryptedOraclePrivKeyBase64)
 	if err != nil {
 		return fmt.Errorf("failed to decode encryptedOraclePrivKey: %w", err)
 	}
 
-	oraclePrivKeyBytes, err := secp256k1.Decrypt(e.encPrivKey, encryptedOraclePrivKey)
+	oraclePrivKeyBytes, env, err := envelope.OpenECIES(e.encPrivKey, encryptedOraclePrivKey)
 	if err != nil {
 		return fmt.Errorf("failed to decrypt oraclePrivKeyBytes: %w", err)
 	}
+	// legacy ciphertexts are not bound to any join, so accepting them would allow a replay from another join
+	if env.Version == envelope.VersionLegacy {
+		return fmt.Errorf("encrypted oracle key for join %v is not bound to the join (legacy envelope)", e.joinID)
+	}
+	if !bytes.Equal(env.AssociatedData, joinIDAssociatedData(e.joinID)) {
+		return fmt.Errorf("encrypted oracle key was not issued for join %v", e.joinID)
+	}
 
 	if err := sgx.SealToFile(oraclePrivKeyBytes, e.oracleKeyFilePath); err != nil {
 		return fmt.Errorf("failed to save oracle key: %w", err)
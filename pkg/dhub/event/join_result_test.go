@@ -0,0 +1,60 @@
+package event
+
+import (
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+	"github.com/youngjoon-lee/doracle-poc/pkg/envelope"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+)
+
+func TestJoinResultRejected(t *testing.T) {
+	encPrivKey, err := secp256k1.NewPrivKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := NewJoinResultEvent(1, encPrivKey, filepath.Join(t.TempDir(), "oracle-key.sealed"))
+
+	err = ev.Apply(oracletypes.JOIN_STATUS_REJECTED, "")
+	if !errors.Is(err, ErrJoinRejected) {
+		t.Fatalf("expected ErrJoinRejected, got %v", err)
+	}
+}
+
+func TestJoinResultLegacyEnvelope(t *testing.T) {
+	encPrivKey, err := secp256k1.NewPrivKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := NewJoinResultEvent(1, encPrivKey, filepath.Join(t.TempDir(), "oracle-key.sealed"))
+
+	legacy, err := secp256k1.Encrypt(encPrivKey.PubKey(), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ev.Apply(oracletypes.JOIN_STATUS_APPROVED, base64.StdEncoding.EncodeToString(legacy))
+	if err == nil || !strings.Contains(err.Error(), "legacy envelope") {
+		t.Fatalf("expected a legacy envelope error, got %v", err)
+	}
+}
+
+func TestJoinResultOtherJoin(t *testing.T) {
+	encPrivKey, err := secp256k1.NewPrivKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ev := NewJoinResultEvent(1, encPrivKey, filepath.Join(t.TempDir(), "oracle-key.sealed"))
+
+	sealed, err := envelope.SealECIES(encPrivKey.PubKey(), 0, joinIDAssociatedData(2), make([]byte, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ev.Apply(oracletypes.JOIN_STATUS_APPROVED, base64.StdEncoding.EncodeToString(sealed))
+	if err == nil || !strings.Contains(err.Error(), "not issued for join 1") {
+		t.Fatalf("expected a join mismatch error, got %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+package event
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+)
+
+type testVote struct {
+	joinID uint64
+	option oracletypes.VoteOption
+	reason string
+}
+
+// stubVoter records votes instead of sending txs, and fails to vote on the joins in failIDs.
+type stubVoter struct {
+	votes   []testVote
+	failIDs map[uint64]bool
+}
+
+func (v *stubVoter) VoteForJoin(joinID uint64, option oracletypes.VoteOption, yesValue, reason string) error {
+	if v.failIDs[joinID] {
+		return errors.New("broadcast failed")
+	}
+	v.votes = append(v.votes, testVote{joinID: joinID, option: option, reason: reason})
+	return nil
+}
+
+func newTestJoinEvent(t *testing.T, voter joinVoter) JoinEvent {
+	t.Helper()
+
+	oraclePrivKey, err := secp256k1.NewPrivKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return JoinEvent{oraclePrivKey: oraclePrivKey, voter: voter}
+}
+
+func TestJoinHandlerMultipleJoins(t *testing.T) {
+	voter := &stubVoter{}
+	ev := newTestJoinEvent(t, voter)
+
+	if err := ev.Handler(joinTxEvent(newTestJoinAttrs(t, "1"), newTestJoinAttrs(t, "2"), newTestJoinAttrs(t, "3"))); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(voter.votes) != 3 {
+		t.Fatalf("expected 3 votes, got %+v", voter.votes)
+	}
+	for i, vote := range voter.votes {
+		if vote.joinID != uint64(i+1) {
+			t.Errorf("vote %v: expected join %v, got %v", i, i+1, vote.joinID)
+		}
+		// the synthesized reports are not genuine
+		if vote.option != oracletypes.OptionNo || vote.reason == "" {
+			t.Errorf("vote %v: expected no with a reason, got %+v", i, vote)
+		}
+	}
+}
+
+func TestJoinHandlerFailureDoesNotStopOthers(t *testing.T) {
+	voter := &stubVoter{failIDs: map[uint64]bool{2: true}}
+	ev := newTestJoinEvent(t, voter)
+
+	err := ev.Handler(joinTxEvent(newTestJoinAttrs(t, "1"), newTestJoinAttrs(t, "2"), newTestJoinAttrs(t, "3")))
+	if err == nil || !strings.Contains(err.Error(), "1 of 3") {
+		t.Fatalf("expected 1 of 3 joins to fail, got %v", err)
+	}
+
+	if len(voter.votes) != 2 || voter.votes[0].joinID != 1 || voter.votes[1].joinID != 3 {
+		t.Fatalf("expected votes on joins 1 and 3, got %+v", voter.votes)
+	}
+}
+
+func TestJoinHandlerMalformedJoin(t *testing.T) {
+	voter := &stubVoter{}
+	ev := newTestJoinEvent(t, voter)
+
+	malformed := newTestJoinAttrs(t, "2")
+	malformed.encPubKey = "not base64!"
+	invalidID := newTestJoinAttrs(t, "x")
+
+	err := ev.Handler(joinTxEvent(newTestJoinAttrs(t, "1"), malformed, invalidID, newTestJoinAttrs(t, "3")))
+	if !errors.Is(err, ErrInvalidEvent) {
+		t.Fatalf("expected ErrInvalidEvent for the invalid ID, got %v", err)
+	}
+
+	if len(voter.votes) != 3 {
+		t.Fatalf("expected votes on joins 1, 2 and 3, got %+v", voter.votes)
+	}
+	vote := voter.votes[1]
+	if vote.joinID != 2 || vote.option != oracletypes.OptionNo || !strings.Contains(vote.reason, "join.enc_pub_key_base64") {
+		t.Fatalf("expected no on the malformed join with the reason, got %+v", vote)
+	}
+}
@@ -3,15 +3,53 @@ package event
 import (
 	"context"
 	"fmt"
+	"sort"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
 )
 
 type Subscriber struct {
-	client *rpchttp.HTTP
+	client rpcClient
+
+	mtx           sync.RWMutex
+	subscriptions map[string]string // name -> query
+	lastHeight    int64
+	watchers      map[chan ObservedEvent]struct{}
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	failedCh chan error
+}
+
+// rpcClient is the part of the tendermint RPC client used by the subscriber.
+type rpcClient interface {
+	Start() error
+	Stop() error
+	Status(ctx context.Context) (*ctypes.ResultStatus, error)
+	Subscribe(ctx context.Context, subscriber, query string, outCapacity ...int) (<-chan ctypes.ResultEvent, error)
+	Unsubscribe(ctx context.Context, subscriber, query string) error
+	TxSearch(ctx context.Context, query string, prove bool, page, perPage *int, orderBy string) (*ctypes.ResultTxSearch, error)
+}
+
+type Subscription struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
 }
 
+// ObservedEvent is an event processed by the subscriber, which is published to watchers.
+type ObservedEvent struct {
+	Subscription string
+	Height       int64
+	Events       map[string][]string
+	Err          error
+}
+
+const watchBufferSize = 64
+
 func NewSubscriber(rpcAddr string) (*Subscriber, error) {
 	client, err := rpchttp.New(rpcAddr, "/websocket")
 	if err != nil {
@@ -19,7 +57,11 @@ func NewSubscriber(rpcAddr string) (*Subscriber, error) {
 	}
 
 	return &Subscriber{
-		client: client,
+		client:        client,
+		subscriptions: make(map[string]string),
+		watchers:      make(map[chan ObservedEvent]struct{}),
+		stopCh:        make(chan struct{}),
+		failedCh:      make(chan error, 1),
 	}, nil
 }
 
@@ -30,46 +72,162 @@ func (s *Subscriber) Start() error {
 
 func (s *Subscriber) Stop() {
 	log.Info("stopping subscriber...")
+	s.stopOnce.Do(func() { close(s.stopCh) })
 	s.client.Stop()
 }
 
 func (s *Subscriber) Subscribe(ev Event) error {
+	// the chain height is queried before subscribing, so that the txs after it can be caught up
+	// if the subscription is restarted before any event arrives
+	status, err := s.client.Status(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to query status: %w", err)
+	}
+
 	resEventCh, err := s.client.Subscribe(context.Background(), ev.Name(), ev.Query())
 	if err != nil {
 		return fmt.Errorf("failed to subscribe: %w", err)
 	}
 
-	go func() {
-		for resEvent := range resEventCh {
-			log.Debugf("event detected: %v", resEvent)
-
-			if err := ev.Handler(resEvent); err != nil {
-				log.Errorf("failed to handle event: %v", err)
-			}
-		}
-	}()
+	s.addSubscription(ev)
+	go s.supervise(ev, resEventCh, newCursor(status.SyncInfo.LatestBlockHeight+1))
 
 	log.Infof("subscription registered: %v / %v", ev.Name(), ev.Query())
 	return nil
 }
 
+// SubscribeOnce waits for the first event of the subscription, and handles it.
+// It returns the error of ctx if ctx is done before any event arrives.
 func (s *Subscriber) SubscribeOnce(ctx context.Context, ev Event) error {
 	resEventCh, err := s.client.Subscribe(ctx, ev.Name(), ev.Query())
 	if err != nil {
 		return fmt.Errorf("failed to subscribe once: %w", err)
 	}
 	defer func() {
-		if err := s.client.Unsubscribe(ctx, ev.Name(), ev.Query()); err != nil {
+		// ctx may be already done here
+		if err := s.client.Unsubscribe(context.Background(), ev.Name(), ev.Query()); err != nil {
 			log.Errorf("failed to unsubscribe: %v", err)
 		}
 	}()
 
-	resEvent := <-resEventCh
+	s.addSubscription(ev)
+	defer s.removeSubscription(ev)
+
+	var resEvent ctypes.ResultEvent
+	select {
+	case resEvent = <-resEventCh:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 	log.Debugf("event detected once: %v", resEvent)
+	s.updateLastHeight(resEvent)
 
-	if err := ev.Handler(resEvent); err != nil {
+	err = s.handle(ev, resEvent)
+	s.publish(ev, resEvent, err)
+	if err != nil {
 		return fmt.Errorf("failed to handle event: %w", err)
 	}
 
 	return nil
 }
+
+// Subscriptions returns the subscriptions which are currently active.
+func (s *Subscriber) Subscriptions() []Subscription {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+
+	subs := make([]Subscription, 0, len(s.subscriptions))
+	for name, query := range s.subscriptions {
+		subs = append(subs, Subscription{Name: name, Query: query})
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Name < subs[j].Name })
+	return subs
+}
+
+// LastHeight returns the height of the last event processed.
+func (s *Subscriber) LastHeight() int64 {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.lastHeight
+}
+
+// Watch returns a channel which receives the events processed from now on, and a function to stop watching.
+// Events are dropped if the channel is not drained in time, so that slow watchers cannot block event handling.
+func (s *Subscriber) Watch() (<-chan ObservedEvent, func()) {
+	ch := make(chan ObservedEvent, watchBufferSize)
+
+	s.mtx.Lock()
+	s.watchers[ch] = struct{}{}
+	s.mtx.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			s.mtx.Lock()
+			delete(s.watchers, ch)
+			s.mtx.Unlock()
+			close(ch)
+		})
+	}
+}
+
+func (s *Subscriber) publish(ev Event, resEvent ctypes.ResultEvent, err error) {
+	observed := ObservedEvent{
+		Subscription: ev.Name(),
+		Height:       eventHeight(resEvent),
+		Events:       resEvent.Events,
+		Err:          err,
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for ch := range s.watchers {
+		select {
+		case ch <- observed:
+		default:
+			log.Warnf("event dropped for a slow watcher: %v", ev.Name())
+		}
+	}
+}
+
+func (s *Subscriber) addSubscription(ev Event) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	s.subscriptions[ev.Name()] = ev.Query()
+}
+
+func (s *Subscriber) removeSubscription(ev Event) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	delete(s.subscriptions, ev.Name())
+}
+
+func (s *Subscriber) updateLastHeight(resEvent ctypes.ResultEvent) {
+	height := eventHeight(resEvent)
+	if height == 0 {
+		return
+	}
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if height > s.lastHeight {
+		s.lastHeight = height
+	}
+}
+
+// eventHeight returns the height of the event, or 0 if the event data has no height.
+func eventHeight(resEvent ctypes.ResultEvent) int64 {
+	switch data := resEvent.Data.(type) {
+	case tmtypes.EventDataTx:
+		return data.Height
+	case tmtypes.EventDataNewBlock:
+		if data.Block == nil {
+			return 0
+		}
+		return data.Block.Height
+	case tmtypes.EventDataNewBlockHeader:
+		return data.Header.Height
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,313 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	abci "github.com/tendermint/tendermint/abci/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+)
+
+// ErrHandlerPanic is returned if an event handler panics. The panic is recovered, so that one bad event
+// doesn't kill the whole node.
+var ErrHandlerPanic = errors.New("event handler panicked")
+
+// errSubscriberFailed is returned while catching up if the subscriber failed by the handler.
+var errSubscriberFailed = errors.New("subscriber failed")
+
+const (
+	// maxFailures is how many failures of a subscription are tolerated in failureWindow
+	// before the subscriber is considered as failed.
+	maxFailures   = 5
+	failureWindow = 10 * time.Minute
+
+	minRestartDelay = time.Second
+	maxRestartDelay = time.Minute
+
+	catchUpPerPage = 100
+	catchUpTimeout = time.Minute
+)
+
+// TxQuerier is implemented by events which can be searched in txs by TxQuery,
+// so that the events emitted while the subscription was being restarted are caught up.
+// TxQuery must not contain tm.event, which TxSearch doesn't support.
+type TxQuerier interface {
+	TxQuery() string
+}
+
+// Failed returns a channel which receives an error if a subscription keeps failing,
+// so that the process can exit rather than running without handling events.
+func (s *Subscriber) Failed() <-chan error {
+	return s.failedCh
+}
+
+// handle runs the handler of the event, recovering from a panic.
+func (s *Subscriber) handle(ev Event, resEvent ctypes.ResultEvent) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("panic in the handler of %v: %v\n%s", ev.Name(), r, debug.Stack())
+			err = fmt.Errorf("%w: %v", ErrHandlerPanic, r)
+		}
+	}()
+	return ev.Handler(resEvent)
+}
+
+// cursor tracks the txs handled by a subscription, so that the txs missed while the subscription is restarted
+// can be searched without handling any tx twice.
+type cursor struct {
+	// height is the lowest height whose txs may not be handled yet.
+	height int64
+	// handled has the hashes of the txs handled at height.
+	handled map[string]bool
+}
+
+func newCursor(height int64) *cursor {
+	return &cursor{height: height, handled: make(map[string]bool)}
+}
+
+// seen returns whether the tx of the event is already handled. Events not from a tx are never seen.
+func (c *cursor) seen(resEvent ctypes.ResultEvent) bool {
+	hash := eventTxHash(resEvent)
+	if hash == "" {
+		return false
+	}
+	return eventHeight(resEvent) < c.height || c.handled[hash]
+}
+
+func (c *cursor) mark(resEvent ctypes.ResultEvent) {
+	hash := eventTxHash(resEvent)
+	if hash == "" {
+		return
+	}
+	if height := eventHeight(resEvent); height > c.height {
+		c.height = height
+		c.handled = make(map[string]bool)
+	}
+	c.handled[hash] = true
+}
+
+// supervise handles the events of the subscription until the subscriber is stopped.
+// If the subscription is closed unexpectedly, it is restarted with a backoff, and the txs missed meanwhile are caught up.
+// Handler panics, failed restarts and gaps which cannot be caught up are counted as failures,
+// and the subscriber fails if they are repeated too often.
+func (s *Subscriber) supervise(ev Event, resEventCh <-chan ctypes.ResultEvent, cur *cursor) {
+	defer s.removeSubscription(ev)
+
+	var failures []time.Time
+	recordFailure := func(err error) bool {
+		now := time.Now()
+		recent := failures[:0]
+		for _, t := range failures {
+			if now.Sub(t) < failureWindow {
+				recent = append(recent, t)
+			}
+		}
+		failures = append(recent, now)
+
+		if len(failures) > maxFailures {
+			s.fail(fmt.Errorf("subscription %v failed %v times in %v: %w", ev.Name(), len(failures), failureWindow, err))
+			return false
+		}
+		return true
+	}
+
+	for {
+		for resEvent := range resEventCh {
+			log.Debugf("event detected: %v", resEvent)
+			// the restarted subscription may deliver txs which were caught up
+			if cur.seen(resEvent) {
+				continue
+			}
+			if !s.process(ev, resEvent, cur, recordFailure) {
+				return
+			}
+		}
+
+		if s.stopped() {
+			return
+		}
+		log.Warnf("subscription closed unexpectedly: %v", ev.Name())
+
+		var ok bool
+		resEventCh, ok = s.resubscribe(ev, recordFailure)
+		if !ok {
+			return
+		}
+		if !s.catchUp(ev, cur, recordFailure) {
+			return
+		}
+	}
+}
+
+// process handles an event, and returns false if the subscriber failed.
+func (s *Subscriber) process(ev Event, resEvent ctypes.ResultEvent, cur *cursor, recordFailure func(error) bool) bool {
+	s.updateLastHeight(resEvent)
+	cur.mark(resEvent)
+
+	err := s.handle(ev, resEvent)
+	if err != nil {
+		log.Errorf("failed to handle event: %v", err)
+	}
+	s.publish(ev, resEvent, err)
+
+	return !errors.Is(err, ErrHandlerPanic) || recordFailure(err)
+}
+
+// catchUp handles the txs which may have been missed while the subscription was down, retrying with a backoff
+// if they cannot be searched. It returns false if the subscriber failed.
+// If the event is not a TxQuerier, the missed events cannot be caught up, so the gap is counted as a failure.
+func (s *Subscriber) catchUp(ev Event, cur *cursor, recordFailure func(error) bool) bool {
+	querier, ok := ev.(TxQuerier)
+	if !ok {
+		err := fmt.Errorf("events of %v emitted while restarting cannot be caught up", ev.Name())
+		log.Error(err)
+		return recordFailure(err)
+	}
+
+	delay := minRestartDelay
+	for {
+		n, err := s.searchMissed(ev, querier, cur, recordFailure)
+		if n > 0 {
+			log.Infof("%v txs of %v caught up", n, ev.Name())
+		}
+		if err == nil {
+			return true
+		}
+
+		log.Errorf("failed to catch up %v: %v", ev.Name(), err)
+		if errors.Is(err, errSubscriberFailed) || !recordFailure(err) {
+			return false
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-s.stopCh:
+			return false
+		}
+		if delay *= 2; delay > maxRestartDelay {
+			delay = maxRestartDelay
+		}
+	}
+}
+
+// searchMissed handles the txs from the height of the cursor which are not handled yet,
+// and returns the number of txs handled.
+// The txs at the height of the cursor are searched as well, since a block can have several txs of the event.
+func (s *Subscriber) searchMissed(ev Event, querier TxQuerier, cur *cursor, recordFailure func(error) bool) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), catchUpTimeout)
+	defer cancel()
+
+	query := fmt.Sprintf("%v AND tx.height>=%v", querier.TxQuery(), cur.height)
+	perPage := catchUpPerPage
+	handled := 0
+	for page, fetched := 1, 0; ; page++ {
+		res, err := s.client.TxSearch(ctx, query, false, &page, &perPage, "asc")
+		if err != nil {
+			return handled, fmt.Errorf("failed to search txs: %v: %w", query, err)
+		}
+
+		for _, txRes := range res.Txs {
+			resEvent := txResultEvent(query, txRes)
+			if cur.seen(resEvent) {
+				continue
+			}
+			log.Infof("catching up %v at height %v", ev.Name(), txRes.Height)
+			handled++
+			if !s.process(ev, resEvent, cur, recordFailure) {
+				return handled, errSubscriberFailed
+			}
+		}
+
+		fetched += len(res.Txs)
+		if len(res.Txs) == 0 || fetched >= res.TotalCount {
+			return handled, nil
+		}
+	}
+}
+
+// txResultEvent converts a tx found by TxSearch to the event which would have been delivered by the subscription.
+func txResultEvent(query string, txRes *ctypes.ResultTx) ctypes.ResultEvent {
+	events := map[string][]string{
+		tmtypes.TxHashKey:   {txRes.Hash.String()},
+		tmtypes.TxHeightKey: {strconv.FormatInt(txRes.Height, 10)},
+	}
+	for _, event := range txRes.TxResult.Events {
+		for _, attr := range event.Attributes {
+			key := event.Type + "." + string(attr.Key)
+			events[key] = append(events[key], string(attr.Value))
+		}
+	}
+
+	return ctypes.ResultEvent{
+		Query: query,
+		Data: tmtypes.EventDataTx{TxResult: abci.TxResult{
+			Height: txRes.Height,
+			Index:  txRes.Index,
+			Tx:     txRes.Tx,
+			Result: txRes.TxResult,
+		}},
+		Events: events,
+	}
+}
+
+// eventTxHash returns the hash of the tx which emitted the event, or an empty string if not a tx event.
+func eventTxHash(resEvent ctypes.ResultEvent) string {
+	if hashes := resEvent.Events[tmtypes.TxHashKey]; len(hashes) > 0 {
+		return hashes[0]
+	}
+	return ""
+}
+
+// resubscribe subscribes to the event again, retrying with a backoff until it succeeds,
+// the subscriber is stopped, or too many failures are recorded.
+func (s *Subscriber) resubscribe(ev Event, recordFailure func(error) bool) (<-chan ctypes.ResultEvent, bool) {
+	delay := minRestartDelay
+	for {
+		select {
+		case <-time.After(delay):
+		case <-s.stopCh:
+			return nil, false
+		}
+
+		// the client refuses to subscribe to the same query twice
+		if err := s.client.Unsubscribe(context.Background(), ev.Name(), ev.Query()); err != nil {
+			log.Debugf("failed to unsubscribe %v before restarting: %v", ev.Name(), err)
+		}
+		resEventCh, err := s.client.Subscribe(context.Background(), ev.Name(), ev.Query())
+		if err == nil {
+			log.Infof("subscription restarted: %v / %v", ev.Name(), ev.Query())
+			return resEventCh, true
+		}
+
+		log.Errorf("failed to restart subscription %v: %v", ev.Name(), err)
+		if !recordFailure(err) {
+			return nil, false
+		}
+		if delay *= 2; delay > maxRestartDelay {
+			delay = maxRestartDelay
+		}
+	}
+}
+
+func (s *Subscriber) fail(err error) {
+	log.Errorf("subscriber failed: %v", err)
+	select {
+	case s.failedCh <- err:
+	default:
+	}
+}
+
+func (s *Subscriber) stopped() bool {
+	select {
+	case <-s.stopCh:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,276 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	abci "github.com/tendermint/tendermint/abci/types"
+	ctypes "github.com/tendermint/tendermint/rpc/core/types"
+	tmtypes "github.com/tendermint/tendermint/types"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+)
+
+// panicEvent panics in its handler, after notifying calls.
+type panicEvent struct {
+	calls chan struct{}
+}
+
+func (e panicEvent) Name() string  { return "panic" }
+func (e panicEvent) Query() string { return "tm.event='Tx'" }
+
+func (e panicEvent) Handler(ctypes.ResultEvent) error {
+	if e.calls != nil {
+		e.calls <- struct{}{}
+	}
+	panic("boom")
+}
+
+// newTestSubscriber creates a subscriber without a client, which is enough as long as no subscription is restarted.
+func newTestSubscriber(t *testing.T) *Subscriber {
+	t.Helper()
+
+	s := &Subscriber{
+		subscriptions: make(map[string]string),
+		watchers:      make(map[chan ObservedEvent]struct{}),
+		stopCh:        make(chan struct{}),
+		failedCh:      make(chan error, 1),
+	}
+	t.Cleanup(func() { s.stopOnce.Do(func() { close(s.stopCh) }) })
+	return s
+}
+
+func TestHandleRecoversPanic(t *testing.T) {
+	s := newTestSubscriber(t)
+
+	if err := s.handle(panicEvent{}, ctypes.ResultEvent{}); !errors.Is(err, ErrHandlerPanic) {
+		t.Fatalf("expected ErrHandlerPanic, got %v", err)
+	}
+}
+
+func TestSuperviseFailsAfterMaxFailures(t *testing.T) {
+	s := newTestSubscriber(t)
+	ev := panicEvent{calls: make(chan struct{}, maxFailures+1)}
+	resEventCh := make(chan ctypes.ResultEvent, maxFailures+1)
+	go s.supervise(ev, resEventCh, newCursor(1))
+
+	// up to maxFailures panics are tolerated
+	for i := 0; i < maxFailures; i++ {
+		resEventCh <- ctypes.ResultEvent{}
+		<-ev.calls
+	}
+	select {
+	case err := <-s.Failed():
+		t.Fatalf("failed after %v panics: %v", maxFailures, err)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	resEventCh <- ctypes.ResultEvent{}
+	select {
+	case err := <-s.Failed():
+		if !errors.Is(err, ErrHandlerPanic) {
+			t.Fatalf("expected ErrHandlerPanic, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("not failed after %v panics", maxFailures+1)
+	}
+}
+
+func TestCatchUpGap(t *testing.T) {
+	s := newTestSubscriber(t)
+
+	// the gap is counted as a failure if missed events cannot be searched
+	failures := 0
+	s.catchUp(panicEvent{}, newCursor(1), func(error) bool {
+		failures++
+		return true
+	})
+	if failures != 1 {
+		t.Fatalf("expected 1 failure, got %v", failures)
+	}
+}
+
+// recordEvent records the hashes of the txs handled.
+type recordEvent struct {
+	handled chan string
+}
+
+func (e recordEvent) Name() string    { return "record" }
+func (e recordEvent) Query() string   { return "tm.event='Tx' AND message.action='record'" }
+func (e recordEvent) TxQuery() string { return "message.action='record'" }
+
+func (e recordEvent) Handler(resEvent ctypes.ResultEvent) error {
+	e.handled <- eventTxHash(resEvent)
+	return nil
+}
+
+// stubClient serves a chain at a fixed height. Each Subscribe returns a new channel, which is sent to subscribed.
+type stubClient struct {
+	height     int64
+	txs        []*ctypes.ResultTx
+	subscribed chan chan ctypes.ResultEvent
+
+	mtx     sync.Mutex
+	queries []string
+}
+
+func (c *stubClient) Start() error { return nil }
+func (c *stubClient) Stop() error  { return nil }
+
+func (c *stubClient) Status(context.Context) (*ctypes.ResultStatus, error) {
+	return &ctypes.ResultStatus{SyncInfo: ctypes.SyncInfo{LatestBlockHeight: c.height}}, nil
+}
+
+func (c *stubClient) Subscribe(context.Context, string, string, ...int) (<-chan ctypes.ResultEvent, error) {
+	ch := make(chan ctypes.ResultEvent, 16)
+	c.subscribed <- ch
+	return ch, nil
+}
+
+func (c *stubClient) Unsubscribe(context.Context, string, string) error { return nil }
+
+// TxSearch returns all txs regardless of the query, and records the query.
+func (c *stubClient) TxSearch(_ context.Context, query string, _ bool, _, _ *int, _ string) (*ctypes.ResultTxSearch, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.queries = append(c.queries, query)
+	return &ctypes.ResultTxSearch{Txs: c.txs, TotalCount: len(c.txs)}, nil
+}
+
+func (c *stubClient) lastQuery() string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	if len(c.queries) == 0 {
+		return ""
+	}
+	return c.queries[len(c.queries)-1]
+}
+
+func testTxResult(name string, height int64) *ctypes.ResultTx {
+	tx := tmtypes.Tx(name)
+	return &ctypes.ResultTx{Hash: tx.Hash(), Height: height, Tx: tx}
+}
+
+func expectHandled(t *testing.T, handled <-chan string, txs ...*ctypes.ResultTx) {
+	t.Helper()
+
+	for _, txRes := range txs {
+		select {
+		case hash := <-handled:
+			if hash != txRes.Hash.String() {
+				t.Fatalf("expected tx %v to be handled, got %v", txRes.Hash, hash)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("tx %v not handled", txRes.Hash)
+		}
+	}
+	select {
+	case hash := <-handled:
+		t.Fatalf("unexpected tx handled: %v", hash)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestRestartBeforeAnyEvent(t *testing.T) {
+	client := &stubClient{height: 10, subscribed: make(chan chan ctypes.ResultEvent, 1)}
+	s := newTestSubscriber(t)
+	s.client = client
+	ev := recordEvent{handled: make(chan string, 16)}
+
+	if err := s.Subscribe(ev); err != nil {
+		t.Fatal(err)
+	}
+	ch := <-client.subscribed
+
+	// txs emitted after subscribing, which are missed since the subscription is closed before they arrive
+	first, second := testTxResult("first", 11), testTxResult("second", 11)
+	client.txs = []*ctypes.ResultTx{first, second}
+	close(ch)
+	ch = <-client.subscribed
+	expectHandled(t, ev.handled, first, second)
+	if query := client.lastQuery(); !strings.HasSuffix(query, "tx.height>=11") {
+		t.Fatalf("expected to search from the height when subscribed, got %q", query)
+	}
+
+	// the restarted subscription may deliver the caught up txs again
+	third := testTxResult("third", 12)
+	ch <- txResultEvent("", second)
+	ch <- txResultEvent("", third)
+	expectHandled(t, ev.handled, third)
+
+	// restarts which are caught up are not failures
+	client.txs = append(client.txs, third)
+	for i := 0; i < maxFailures+1; i++ {
+		close(ch)
+		ch = <-client.subscribed
+	}
+	if query := client.lastQuery(); !strings.HasSuffix(query, "tx.height>=12") {
+		t.Fatalf("expected to search from the last height handled, got %q", query)
+	}
+	expectHandled(t, ev.handled)
+	select {
+	case err := <-s.Failed():
+		t.Fatalf("failed after restarts: %v", err)
+	default:
+	}
+}
+
+func TestCatchUpSameBlock(t *testing.T) {
+	client := &stubClient{height: 10, subscribed: make(chan chan ctypes.ResultEvent, 1)}
+	s := newTestSubscriber(t)
+	s.client = client
+	ev := recordEvent{handled: make(chan string, 16)}
+
+	if err := s.Subscribe(ev); err != nil {
+		t.Fatal(err)
+	}
+	ch := <-client.subscribed
+
+	// the subscription is closed after the first tx of a block, before the second tx of the same block arrives
+	first, second := testTxResult("first", 11), testTxResult("second", 11)
+	ch <- txResultEvent("", first)
+	expectHandled(t, ev.handled, first)
+
+	client.txs = []*ctypes.ResultTx{first, second}
+	close(ch)
+	<-client.subscribed
+	expectHandled(t, ev.handled, second)
+}
+
+func TestTxResultEvent(t *testing.T) {
+	attrs := []testJoinAttrs{newTestJoinAttrs(t, "1"), newTestJoinAttrs(t, "2")}
+
+	var events []abci.Event
+	for _, join := range attrs {
+		events = append(events, abci.Event{
+			Type: oracletypes.EventTypeJoin,
+			Attributes: []abci.EventAttribute{
+				{Key: []byte(oracletypes.AttributeKeyID), Value: []byte(join.id)},
+				{Key: []byte(oracletypes.AttributeKeyOperatorAddress), Value: []byte(join.operator)},
+				{Key: []byte(oracletypes.AttributeKeyEnclaveReportBase64), Value: []byte(join.report)},
+				{Key: []byte(oracletypes.AttributeKeyEncPubKeyBase64), Value: []byte(join.encPubKey)},
+			},
+		})
+	}
+	tx := tmtypes.Tx("tx")
+	txRes := &ctypes.ResultTx{Hash: tx.Hash(), Height: 7, Tx: tx, TxResult: abci.ResponseDeliverTx{Events: events}}
+
+	resEvent := txResultEvent("query", txRes)
+	if eventHeight(resEvent) != 7 {
+		t.Fatalf("expected height 7, got %v", eventHeight(resEvent))
+	}
+	if eventTxHash(resEvent) != txRes.Hash.String() {
+		t.Fatalf("expected tx hash %v, got %v", txRes.Hash, eventTxHash(resEvent))
+	}
+
+	joins, err := DecodeJoinEvents(resEvent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(joins) != 2 || joins[0].ID != 1 || joins[1].ID != 2 {
+		t.Fatalf("unexpected joins: %+v", joins)
+	}
+}
@@ -0,0 +1,187 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/cosmos/cosmos-sdk/client"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	sdkquery "github.com/cosmos/cosmos-sdk/types/query"
+	"github.com/ignite-hq/cli/ignite/pkg/cosmoscmd"
+	"github.com/youngjoon-lee/dhub/app"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+)
+
+// Client queries the state of the dhub oracle module.
+type Client struct {
+	clientCtx    client.Context
+	oracleClient oracletypes.QueryClient
+}
+
+const (
+	pageLimit     = 100
+	txSearchLimit = 100
+)
+
+func NewClient(rpcAddr string) (Client, error) {
+	rpcClient, err := client.NewClientFromNode(rpcAddr)
+	if err != nil {
+		return Client{}, fmt.Errorf("failed to NewClientFromNode: %w", err)
+	}
+
+	encodingConfig := cosmoscmd.MakeEncodingConfig(app.ModuleBasics)
+	clientCtx := client.Context{}.
+		WithClient(rpcClient).
+		WithCodec(encodingConfig.Marshaler).
+		WithInterfaceRegistry(encodingConfig.InterfaceRegistry).
+		WithTxConfig(encodingConfig.TxConfig)
+
+	return NewClientFromContext(clientCtx), nil
+}
+
+// NewClientFromContext creates a client which shares the RPC client and the codec of clientCtx,
+// such as the one built by the tx executor.
+func NewClientFromContext(clientCtx client.Context) Client {
+	return Client{
+		clientCtx:    clientCtx,
+		oracleClient: oracletypes.NewQueryClient(clientCtx),
+	}
+}
+
+// OraclePubKey returns the oracle public key registered on chain by the first oracle.
+func (c Client) OraclePubKey(ctx context.Context) (*btcec.PublicKey, error) {
+	res, err := c.oracleClient.OraclePubKey(ctx, &oracletypes.QueryGetOraclePubKeyRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query oracle pubkey: %w", err)
+	}
+	if res.PubKey.PubKey == nil {
+		return nil, fmt.Errorf("oracle pubkey is empty")
+	}
+
+	pubKey, err := secp256k1.PubKeyFromBytes(res.PubKey.PubKey.Key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oracle pubkey: %w", err)
+	}
+	return pubKey, nil
+}
+
+// Oracles returns all members of the oracle group.
+func (c Client) Oracles(ctx context.Context) ([]oracletypes.Oracle, error) {
+	var oracles []oracletypes.Oracle
+	var nextKey []byte
+	for {
+		res, err := c.oracleClient.OracleAll(ctx, &oracletypes.QueryAllOracleRequest{
+			Pagination: &sdkquery.PageRequest{Key: nextKey, Limit: pageLimit},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query oracles: %w", err)
+		}
+		oracles = append(oracles, res.Oracle...)
+
+		if res.Pagination == nil || len(res.Pagination.NextKey) == 0 {
+			return oracles, nil
+		}
+		nextKey = res.Pagination.NextKey
+	}
+}
+
+// Join returns the join request of the ID, including its status and tally result.
+func (c Client) Join(ctx context.Context, id uint64) (oracletypes.Join, error) {
+	res, err := c.oracleClient.Join(ctx, &oracletypes.QueryGetJoinRequest{Id: id})
+	if err != nil {
+		return oracletypes.Join{}, fmt.Errorf("failed to query join %v: %w", id, err)
+	}
+	return res.Join, nil
+}
+
+// Params returns the params of the oracle module.
+func (c Client) Params(ctx context.Context) (oracletypes.Params, error) {
+	res, err := c.oracleClient.Params(ctx, &oracletypes.QueryParamsRequest{})
+	if err != nil {
+		return oracletypes.Params{}, fmt.Errorf("failed to query params: %w", err)
+	}
+	return res.Params, nil
+}
+
+// Vote is a vote cast on a join.
+type Vote struct {
+	JoinID uint64
+	Voter  string
+	Option oracletypes.VoteOption
+	Height int64
+	TxHash string
+	// Reason is the memo of the vote tx, where voters publish why they voted no.
+	Reason string
+}
+
+// Votes returns the votes cast on the join, in the order they were included in blocks.
+// The oracle module doesn't expose votes by gRPC, so they are found from the vote_for_join events of txs.
+func (c Client) Votes(ctx context.Context, joinID uint64) ([]Vote, error) {
+	query := fmt.Sprintf("%v.%v=%v", oracletypes.EventTypeVoteForJoin, oracletypes.AttributeKeyID, joinID)
+	idValue := strconv.FormatUint(joinID, 10)
+
+	var votes []Vote
+	perPage := txSearchLimit
+	for page, fetched := 1, 0; ; page++ {
+		res, err := c.clientCtx.Client.TxSearch(ctx, query, false, &page, &perPage, "asc")
+		if err != nil {
+			return nil, fmt.Errorf("failed to search votes for join %v: %w", joinID, err)
+		}
+
+		for _, txRes := range res.Txs {
+			reason := c.txMemo(txRes.Tx)
+			for _, ev := range txRes.TxResult.Events {
+				if ev.Type != oracletypes.EventTypeVoteForJoin {
+					continue
+				}
+
+				vote := Vote{Height: txRes.Height, TxHash: txRes.Hash.String(), Reason: reason}
+				var id, option string
+				for _, attr := range ev.Attributes {
+					switch string(attr.Key) {
+					case oracletypes.AttributeKeyID:
+						id = string(attr.Value)
+					case oracletypes.AttributeKeyVoter:
+						vote.Voter = string(attr.Value)
+					case oracletypes.AttributeKeyOption:
+						option = string(attr.Value)
+					}
+				}
+				// a tx may contain votes for other joins
+				if id != idValue {
+					continue
+				}
+				optionValue, ok := oracletypes.VoteOption_value[option]
+				if !ok {
+					return nil, fmt.Errorf("invalid vote option in tx %v: %q", vote.TxHash, option)
+				}
+				vote.Option = oracletypes.VoteOption(optionValue)
+				vote.JoinID = joinID
+				votes = append(votes, vote)
+			}
+		}
+
+		fetched += len(res.Txs)
+		if len(res.Txs) == 0 || fetched >= res.TotalCount {
+			return votes, nil
+		}
+	}
+}
+
+// txMemo returns the memo of the tx, or an empty string if the tx cannot be decoded.
+func (c Client) txMemo(txBytes []byte) string {
+	if c.clientCtx.TxConfig == nil {
+		return ""
+	}
+	decoded, err := c.clientCtx.TxConfig.TxDecoder()(txBytes)
+	if err != nil {
+		return ""
+	}
+	if txWithMemo, ok := decoded.(sdk.TxWithMemo); ok {
+		return txWithMemo.GetMemo()
+	}
+	return ""
+}
@@ -2,10 +2,9 @@ package tx
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"github.com/cosmos/cosmos-sdk/client"
-	"github.com/cosmos/cosmos-sdk/client/tx"
-	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/cosmos/cosmos-sdk/types/tx/signing"
 	authsigning "github.com/cosmos/cosmos-sdk/x/auth/signing"
@@ -16,20 +15,17 @@ import (
 	"github.com/youngjoon-lee/dhub/app"
 )
 
-const (
-	gasLimit = 500000
-	denom    = "uhub"
-)
-
 type Executor struct {
 	rpcClient      rpcclient.Client
 	chainID        string
 	encodingConfig cosmoscmd.EncodingConfig
-	signer         sdk.AccAddress
-	signerPrivKey  cryptotypes.PrivKey
+	signer         Signer
+	gasLimit       uint64
+	fees           sdk.Coins
+	pendingTxs     *int64
 }
 
-func NewExecutor(rpcAddr, chainID string, signer sdk.AccAddress, signerPrivKey cryptotypes.PrivKey) (Executor, error) {
+func NewExecutor(rpcAddr, chainID string, signer Signer, gasLimit uint64, fees sdk.Coins) (Executor, error) {
 	rpcClient, err := client.NewClientFromNode(rpcAddr)
 	if err != nil {
 		return Executor{}, fmt.Errorf("failed to NewClientFromNode: %w", err)
@@ -40,7 +36,9 @@ func NewExecutor(rpcAddr, chainID string, signer sdk.AccAddress, signerPrivKey c
 		chainID:        chainID,
 		encodingConfig: cosmoscmd.MakeEncodingConfig(app.ModuleBasics),
 		signer:         signer,
-		signerPrivKey:  signerPrivKey,
+		gasLimit:       gasLimit,
+		fees:           fees,
+		pendingTxs:     new(int64),
 	}, nil
 }
 
@@ -55,11 +53,25 @@ func (e Executor) Context() client.Context {
 		WithBroadcastMode("block")
 }
 
+// Signer returns the address of the operator account which signs txs.
 func (e Executor) Signer() sdk.AccAddress {
-	return e.signer
+	return e.signer.Address()
+}
+
+func (e Executor) ChainID() string {
+	return e.chainID
 }
 
-func (e Executor) signAndBroadcastTx(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+// PendingTxs returns the number of txs being signed and broadcast.
+func (e Executor) PendingTxs() int64 {
+	return atomic.LoadInt64(e.pendingTxs)
+}
+
+// signAndBroadcastTx signs and broadcasts a tx of the msgs. The memo is optional.
+func (e Executor) signAndBroadcastTx(memo string, msgs ...sdk.Msg) (*sdk.TxResponse, error) {
+	atomic.AddInt64(e.pendingTxs, 1)
+	defer atomic.AddInt64(e.pendingTxs, -1)
+
 	clientCtx := e.Context()
 
 	txBuilder := e.encodingConfig.TxConfig.NewTxBuilder()
@@ -67,12 +79,13 @@ func (e Executor) signAndBroadcastTx(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
 		return nil, fmt.Errorf("failed to set msgs: %w", err)
 	}
 
-	//TODO: set fee
-	txBuilder.SetFeeAmount(sdk.NewCoins(sdk.NewCoin(denom, sdk.ZeroInt())))
-	txBuilder.SetGasLimit(gasLimit)
+	txBuilder.SetMemo(memo)
+	txBuilder.SetFeeAmount(e.fees)
+	txBuilder.SetGasLimit(e.gasLimit)
 
-	log.Debugf("retrieving account: %v", e.signer.String())
-	accNum, accSeq, err := authtypes.AccountRetriever{}.GetAccountNumberSequence(clientCtx, e.signer)
+	signerAddr := e.signer.Address()
+	log.Debugf("retrieving account: %v", signerAddr.String())
+	accNum, accSeq, err := authtypes.AccountRetriever{}.GetAccountNumberSequence(clientCtx, signerAddr)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get account number/sequence: %w", err)
 	}
@@ -81,7 +94,7 @@ func (e Executor) signAndBroadcastTx(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
 	// First round: gather all the signer infos by using the "set empty signature" hack to do that.
 	var sigsV2 []signing.SignatureV2
 	sigV2 := signing.SignatureV2{
-		PubKey: e.signerPrivKey.PubKey(),
+		PubKey: e.signer.PubKey(),
 		Data: &signing.SingleSignatureData{
 			SignMode:  clientCtx.TxConfig.SignModeHandler().DefaultMode(),
 			Signature: nil,
@@ -101,12 +114,22 @@ func (e Executor) signAndBroadcastTx(msgs ...sdk.Msg) (*sdk.TxResponse, error) {
 		AccountNumber: accNum,
 		Sequence:      accSeq,
 	}
-	sigV2, err = tx.SignWithPrivKey(
-		clientCtx.TxConfig.SignModeHandler().DefaultMode(), signerData,
-		txBuilder, e.signerPrivKey, clientCtx.TxConfig, accSeq,
-	)
+	signMode := clientCtx.TxConfig.SignModeHandler().DefaultMode()
+	signBytes, err := clientCtx.TxConfig.SignModeHandler().GetSignBytes(signMode, signerData, txBuilder.GetTx())
 	if err != nil {
-		return nil, fmt.Errorf("failed to sign with privkey: %w", err)
+		return nil, fmt.Errorf("failed to get sign bytes: %w", err)
+	}
+	sig, err := e.signer.Sign(signBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	sigV2 = signing.SignatureV2{
+		PubKey: e.signer.PubKey(),
+		Data: &signing.SingleSignatureData{
+			SignMode:  signMode,
+			Signature: sig,
+		},
+		Sequence: accSeq,
 	}
 	sigsV2 = append(sigsV2, sigV2)
 
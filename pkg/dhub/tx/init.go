@@ -11,7 +11,7 @@ import (
 func (e Executor) Init(operatorAddress string, enclaveReport []byte, oraclePubKey *secp256k1.PubKey) error {
 	msg := oracletypes.NewMsgInit(operatorAddress, enclaveReport, oraclePubKey)
 
-	res, err := e.signAndBroadcastTx(msg)
+	res, err := e.signAndBroadcastTx("", msg)
 	if err != nil {
 		return fmt.Errorf("failed to sign and broadcast tx: %w", err)
 	}
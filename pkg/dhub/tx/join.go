@@ -13,7 +13,7 @@ import (
 func (e Executor) Join(operatorAddress string, enclaveReport []byte, encPubKey *secp256k1.PubKey) (uint64, error) {
 	msg := oracletypes.NewMsgJoin(operatorAddress, enclaveReport, encPubKey)
 
-	res, err := e.signAndBroadcastTx(msg)
+	res, err := e.signAndBroadcastTx("", msg)
 	if err != nil {
 		return 0, fmt.Errorf("failed to sign and broadcast tx: %w", err)
 	}
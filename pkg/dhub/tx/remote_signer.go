@@ -0,0 +1,317 @@
+package tx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	cosmossecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	"github.com/gogo/protobuf/proto"
+	log "github.com/sirupsen/logrus"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+)
+
+// The remote signer protocol is newline-delimited JSON over a local socket.
+// Each connection carries requests and responses one at a time:
+//
+//	-> {"method":"pub_key"}
+//	<- {"pub_key":"<base64 compressed secp256k1 pubkey>"}
+//	-> {"method":"sign","sign_bytes":"<base64 SignDoc>"}
+//	<- {"signature":"<base64>"}
+//
+// A response has "error" set if the request was rejected.
+//
+// The protocol is not authenticated, so it is served only over a unix socket or a loopback TCP address.
+const (
+	remoteMethodPubKey = "pub_key"
+	remoteMethodSign   = "sign"
+
+	remoteSignerTimeout = 30 * time.Second
+)
+
+type remoteRequest struct {
+	Method    string `json:"method"`
+	SignBytes []byte `json:"sign_bytes,omitempty"`
+}
+
+type remoteResponse struct {
+	PubKey    []byte `json:"pub_key,omitempty"`
+	Signature []byte `json:"signature,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// RemoteSigner signs with a key held by another process, such as a signer on a hardware wallet,
+// so that the operator account key can be kept off the oracle host.
+type RemoteSigner struct {
+	network string
+	addr    string
+	pubKey  cryptotypes.PubKey
+
+	mtx    sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+var _ Signer = &RemoteSigner{}
+
+// NewRemoteSigner connects to the remote signer at addr (unix:///path/to/socket or tcp://host:port),
+// and retrieves the public key of the signer.
+func NewRemoteSigner(addr string) (*RemoteSigner, error) {
+	network, address, err := parseSignerAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &RemoteSigner{network: network, addr: address}
+	res, err := s.call(remoteRequest{Method: remoteMethodPubKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pubkey from remote signer: %w", err)
+	}
+	if len(res.PubKey) != cosmossecp256k1.PubKeySize {
+		return nil, fmt.Errorf("invalid pubkey from remote signer: %x", res.PubKey)
+	}
+	s.pubKey = &cosmossecp256k1.PubKey{Key: res.PubKey}
+
+	return s, nil
+}
+
+func (s *RemoteSigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.pubKey.Address())
+}
+
+func (s *RemoteSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+func (s *RemoteSigner) Sign(signBytes []byte) ([]byte, error) {
+	res, err := s.call(remoteRequest{Method: remoteMethodSign, SignBytes: signBytes})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with remote signer: %w", err)
+	}
+	if !s.pubKey.VerifySignature(signBytes, res.Signature) {
+		return nil, fmt.Errorf("invalid signature from remote signer")
+	}
+	return res.Signature, nil
+}
+
+func (s *RemoteSigner) Close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+// call sends the request over the connection, which is re-established if it was broken.
+func (s *RemoteSigner) call(req remoteRequest) (remoteResponse, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.conn == nil {
+		conn, err := net.DialTimeout(s.network, s.addr, remoteSignerTimeout)
+		if err != nil {
+			return remoteResponse{}, fmt.Errorf("failed to connect to %v: %w", s.addr, err)
+		}
+		s.conn = conn
+		s.reader = bufio.NewReader(conn)
+	}
+
+	res, err := roundTrip(s.conn, s.reader, req)
+	if err != nil {
+		s.conn.Close()
+		s.conn = nil
+		return remoteResponse{}, err
+	}
+	if res.Error != "" {
+		return remoteResponse{}, fmt.Errorf("rejected by remote signer: %v", res.Error)
+	}
+	return res, nil
+}
+
+func roundTrip(conn net.Conn, reader *bufio.Reader, req remoteRequest) (remoteResponse, error) {
+	if err := conn.SetDeadline(time.Now().Add(remoteSignerTimeout)); err != nil {
+		return remoteResponse{}, fmt.Errorf("failed to set deadline: %w", err)
+	}
+
+	bz, err := json.Marshal(req)
+	if err != nil {
+		return remoteResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	if _, err := conn.Write(append(bz, '\n')); err != nil {
+		return remoteResponse{}, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	line, err := reader.ReadBytes('\n')
+	if err != nil {
+		return remoteResponse{}, fmt.Errorf("failed to receive response: %w", err)
+	}
+	var res remoteResponse
+	if err := json.Unmarshal(line, &res); err != nil {
+		return remoteResponse{}, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	return res, nil
+}
+
+// allowedRemoteMsgs are the only messages that RemoteSignerServer signs, so that a client cannot make it sign
+// a tx which moves the funds of the operator account.
+var allowedRemoteMsgs = map[string]func() sdk.Msg{
+	sdk.MsgTypeURL(&oracletypes.MsgInit{}):        func() sdk.Msg { return &oracletypes.MsgInit{} },
+	sdk.MsgTypeURL(&oracletypes.MsgJoin{}):        func() sdk.Msg { return &oracletypes.MsgJoin{} },
+	sdk.MsgTypeURL(&oracletypes.MsgVoteForJoin{}): func() sdk.Msg { return &oracletypes.MsgVoteForJoin{} },
+}
+
+// RemoteSignerServer serves the remote signer protocol with a signer.
+// It signs only the SignDocs of the allowed chain, which contain only oracle messages signed by the signer.
+type RemoteSignerServer struct {
+	signer         Signer
+	allowedChainID string
+}
+
+func NewRemoteSignerServer(signer Signer, allowedChainID string) *RemoteSignerServer {
+	return &RemoteSignerServer{
+		signer:         signer,
+		allowedChainID: allowedChainID,
+	}
+}
+
+// Serve accepts connections until the listener is closed.
+func (s *RemoteSignerServer) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *RemoteSignerServer) serveConn(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return
+		}
+
+		var req remoteRequest
+		var res remoteResponse
+		if err := json.Unmarshal(line, &req); err != nil {
+			res.Error = fmt.Sprintf("invalid request: %v", err)
+		} else {
+			res = s.handle(req)
+		}
+
+		bz, err := json.Marshal(res)
+		if err != nil {
+			log.Errorf("failed to marshal response: %v", err)
+			return
+		}
+		if _, err := conn.Write(append(bz, '\n')); err != nil {
+			log.Errorf("failed to send response: %v", err)
+			return
+		}
+	}
+}
+
+func (s *RemoteSignerServer) handle(req remoteRequest) remoteResponse {
+	switch req.Method {
+	case remoteMethodPubKey:
+		return remoteResponse{PubKey: s.signer.PubKey().Bytes()}
+	case remoteMethodSign:
+		var signDoc txtypes.SignDoc
+		if err := signDoc.Unmarshal(req.SignBytes); err != nil {
+			return remoteResponse{Error: fmt.Sprintf("invalid sign doc: %v", err)}
+		}
+		if signDoc.ChainId != s.allowedChainID {
+			return remoteResponse{Error: fmt.Sprintf("chain ID not allowed: %v", signDoc.ChainId)}
+		}
+		if err := s.checkBody(signDoc.BodyBytes); err != nil {
+			return remoteResponse{Error: err.Error()}
+		}
+
+		sig, err := s.signer.Sign(req.SignBytes)
+		if err != nil {
+			return remoteResponse{Error: err.Error()}
+		}
+		log.Infof("signed a tx for %v: account number %v", signDoc.ChainId, signDoc.AccountNumber)
+		return remoteResponse{Signature: sig}
+	default:
+		return remoteResponse{Error: fmt.Sprintf("unknown method: %v", req.Method)}
+	}
+}
+
+// checkBody rejects the tx body unless all its messages are allowed and signed only by the signer.
+func (s *RemoteSignerServer) checkBody(bodyBytes []byte) error {
+	var body txtypes.TxBody
+	if err := body.Unmarshal(bodyBytes); err != nil {
+		return fmt.Errorf("invalid tx body: %w", err)
+	}
+	if len(body.Messages) == 0 {
+		return fmt.Errorf("no message in tx")
+	}
+	if len(body.ExtensionOptions) > 0 || len(body.NonCriticalExtensionOptions) > 0 {
+		return fmt.Errorf("extension options not allowed")
+	}
+
+	for _, anyMsg := range body.Messages {
+		newMsg, ok := allowedRemoteMsgs[anyMsg.TypeUrl]
+		if !ok {
+			return fmt.Errorf("message not allowed: %v", anyMsg.TypeUrl)
+		}
+		msg := newMsg()
+		if err := proto.Unmarshal(anyMsg.Value, msg); err != nil {
+			return fmt.Errorf("invalid %v: %w", anyMsg.TypeUrl, err)
+		}
+		for _, signer := range msg.GetSigners() {
+			if !signer.Equals(s.signer.Address()) {
+				return fmt.Errorf("%v must be signed only by %v, not %v", anyMsg.TypeUrl, s.signer.Address(), signer)
+			}
+		}
+	}
+	return nil
+}
+
+// ListenSigner listens on addr (unix:///path/to/socket or tcp://host:port) for the remote signer protocol.
+func ListenSigner(addr string) (net.Listener, error) {
+	network, address, err := parseSignerAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	return net.Listen(network, address)
+}
+
+func parseSignerAddr(addr string) (string, string, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid remote signer addr %v: %w", addr, err)
+	}
+	switch u.Scheme {
+	case "unix":
+		return "unix", u.Path, nil
+	case "tcp":
+		// the protocol is neither encrypted nor authenticated
+		host, _, err := net.SplitHostPort(u.Host)
+		if err != nil {
+			return "", "", fmt.Errorf("invalid remote signer addr %v: %w", addr, err)
+		}
+		if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			return "", "", fmt.Errorf("remote signer addr must be a unix socket or a loopback TCP addr: %v", addr)
+		}
+		return "tcp", u.Host, nil
+	default:
+		return "", "", fmt.Errorf("remote signer addr must be unix:// or tcp://: %v", addr)
+	}
+}
@@ -0,0 +1,183 @@
+package tx
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	cosmossecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	txtypes "github.com/cosmos/cosmos-sdk/types/tx"
+	banktypes "github.com/cosmos/cosmos-sdk/x/bank/types"
+	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
+)
+
+const testChainID = "dhub-test"
+
+// startTestRemoteSigner serves the remote signer protocol with the signer on a temp unix socket,
+// and returns the addr of the socket.
+func startTestRemoteSigner(t *testing.T, signer Signer) string {
+	t.Helper()
+
+	// os.MkdirTemp instead of t.TempDir, which can exceed the max length of unix socket paths
+	dir, err := os.MkdirTemp("", "signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	addr := "unix://" + filepath.Join(dir, "signer.sock")
+	listener, err := ListenSigner(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go NewRemoteSignerServer(signer, testChainID).Serve(listener)
+	return addr
+}
+
+func newTestRemoteSigner(t *testing.T, addr string) *RemoteSigner {
+	t.Helper()
+
+	remoteSigner, err := NewRemoteSigner(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { remoteSigner.Close() })
+	return remoteSigner
+}
+
+func testSignBytes(t *testing.T, chainID string, msgs ...sdk.Msg) []byte {
+	t.Helper()
+
+	body := txtypes.TxBody{}
+	for _, msg := range msgs {
+		anyMsg, err := codectypes.NewAnyWithValue(msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body.Messages = append(body.Messages, anyMsg)
+	}
+	bodyBytes, err := body.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signDoc := txtypes.SignDoc{BodyBytes: bodyBytes, ChainId: chainID, AccountNumber: 1}
+	signBytes, err := signDoc.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signBytes
+}
+
+func TestRemoteSignerPubKey(t *testing.T) {
+	signer := NewPrivKeySigner(cosmossecp256k1.GenPrivKey())
+	remoteSigner := newTestRemoteSigner(t, startTestRemoteSigner(t, signer))
+
+	if !remoteSigner.PubKey().Equals(signer.PubKey()) {
+		t.Fatalf("pubkey mismatch: %v != %v", remoteSigner.PubKey(), signer.PubKey())
+	}
+	if !remoteSigner.Address().Equals(signer.Address()) {
+		t.Fatalf("address mismatch: %v != %v", remoteSigner.Address(), signer.Address())
+	}
+}
+
+func TestRemoteSignerSign(t *testing.T) {
+	signer := NewPrivKeySigner(cosmossecp256k1.GenPrivKey())
+	remoteSigner := newTestRemoteSigner(t, startTestRemoteSigner(t, signer))
+
+	msg := oracletypes.NewMsgVoteForJoin(1, oracletypes.OptionYes, "", signer.Address().String())
+	signBytes := testSignBytes(t, testChainID, msg)
+
+	sig, err := remoteSigner.Sign(signBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !signer.PubKey().VerifySignature(signBytes, sig) {
+		t.Fatal("invalid signature")
+	}
+}
+
+func TestRemoteSignerRejected(t *testing.T) {
+	signer := NewPrivKeySigner(cosmossecp256k1.GenPrivKey())
+	other := NewPrivKeySigner(cosmossecp256k1.GenPrivKey())
+	remoteSigner := newTestRemoteSigner(t, startTestRemoteSigner(t, signer))
+
+	vote := oracletypes.NewMsgVoteForJoin(1, oracletypes.OptionYes, "", signer.Address().String())
+	testCases := map[string]struct {
+		signBytes []byte
+		errMsg    string
+	}{
+		"other chain": {
+			signBytes: testSignBytes(t, "other-chain", vote),
+			errMsg:    "chain ID not allowed",
+		},
+		"transfer": {
+			signBytes: testSignBytes(t, testChainID, vote, banktypes.NewMsgSend(signer.Address(), other.Address(), sdk.NewCoins(sdk.NewInt64Coin("uhub", 1)))),
+			errMsg:    "message not allowed",
+		},
+		"other signer": {
+			signBytes: testSignBytes(t, testChainID, oracletypes.NewMsgVoteForJoin(1, oracletypes.OptionYes, "", other.Address().String())),
+			errMsg:    "must be signed only by",
+		},
+		"no message": {
+			signBytes: testSignBytes(t, testChainID),
+			errMsg:    "no message",
+		},
+		"not a sign doc": {
+			signBytes: []byte("not a sign doc"),
+			errMsg:    "invalid sign doc",
+		},
+	}
+
+	for name, tc := range testCases {
+		if _, err := remoteSigner.Sign(tc.signBytes); err == nil || !strings.Contains(err.Error(), tc.errMsg) {
+			t.Errorf("%v: expected %q, got %v", name, tc.errMsg, err)
+		}
+	}
+
+	// the connection is still usable after rejections
+	if _, err := remoteSigner.Sign(testSignBytes(t, testChainID, vote)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// wrongKeySigner reports the pubkey of one key, but signs with another.
+type wrongKeySigner struct {
+	PrivKeySigner
+	wrong PrivKeySigner
+}
+
+func (s wrongKeySigner) Sign(signBytes []byte) ([]byte, error) {
+	return s.wrong.Sign(signBytes)
+}
+
+func TestRemoteSignerBadSignature(t *testing.T) {
+	signer := wrongKeySigner{
+		PrivKeySigner: NewPrivKeySigner(cosmossecp256k1.GenPrivKey()),
+		wrong:         NewPrivKeySigner(cosmossecp256k1.GenPrivKey()),
+	}
+	remoteSigner := newTestRemoteSigner(t, startTestRemoteSigner(t, signer))
+
+	msg := oracletypes.NewMsgVoteForJoin(1, oracletypes.OptionYes, "", signer.Address().String())
+	if _, err := remoteSigner.Sign(testSignBytes(t, testChainID, msg)); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Fatalf("expected an invalid signature error, got %v", err)
+	}
+}
+
+func TestParseSignerAddr(t *testing.T) {
+	for _, addr := range []string{"unix:///tmp/signer.sock", "tcp://127.0.0.1:26659", "tcp://localhost:26659", "tcp://[::1]:26659"} {
+		if _, _, err := parseSignerAddr(addr); err != nil {
+			t.Errorf("%v: %v", addr, err)
+		}
+	}
+	for _, addr := range []string{"tcp://0.0.0.0:26659", "tcp://10.0.0.1:26659", "tcp://signer.example.com:26659", "tcp://127.0.0.1", "http://127.0.0.1:26659"} {
+		if _, _, err := parseSignerAddr(addr); err == nil {
+			t.Errorf("%v: accepted", addr)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package tx
+
+import (
+	"fmt"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+)
+
+// Signer signs txs on behalf of the operator account, so that the executor doesn't need to hold the private key.
+type Signer interface {
+	Address() sdk.AccAddress
+	PubKey() cryptotypes.PubKey
+	// Sign signs the sign bytes of a tx (SIGN_MODE_DIRECT).
+	Sign(signBytes []byte) ([]byte, error)
+}
+
+// PrivKeySigner signs with the private key held in memory.
+type PrivKeySigner struct {
+	privKey cryptotypes.PrivKey
+}
+
+var _ Signer = PrivKeySigner{}
+
+func NewPrivKeySigner(privKey cryptotypes.PrivKey) PrivKeySigner {
+	return PrivKeySigner{privKey: privKey}
+}
+
+func (s PrivKeySigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.privKey.PubKey().Address())
+}
+
+func (s PrivKeySigner) PubKey() cryptotypes.PubKey {
+	return s.privKey.PubKey()
+}
+
+func (s PrivKeySigner) Sign(signBytes []byte) ([]byte, error) {
+	return s.privKey.Sign(signBytes)
+}
+
+// KeyringSigner signs with a key in the Cosmos SDK keyring, without exporting the key from the keyring.
+type KeyringSigner struct {
+	keyring keyring.Keyring
+	name    string
+	pubKey  cryptotypes.PubKey
+}
+
+var _ Signer = KeyringSigner{}
+
+func NewKeyringSigner(kr keyring.Keyring, name string) (KeyringSigner, error) {
+	info, err := kr.Key(name)
+	if err != nil {
+		return KeyringSigner{}, fmt.Errorf("failed to get key %v from keyring: %w", name, err)
+	}
+	return KeyringSigner{
+		keyring: kr,
+		name:    name,
+		pubKey:  info.GetPubKey(),
+	}, nil
+}
+
+func (s KeyringSigner) Address() sdk.AccAddress {
+	return sdk.AccAddress(s.pubKey.Address())
+}
+
+func (s KeyringSigner) PubKey() cryptotypes.PubKey {
+	return s.pubKey
+}
+
+func (s KeyringSigner) Sign(signBytes []byte) ([]byte, error) {
+	sig, _, err := s.keyring.Sign(s.name, signBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign with keyring: %w", err)
+	}
+	return sig, nil
+}
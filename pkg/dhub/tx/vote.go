@@ -2,15 +2,21 @@ package tx
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	log "github.com/sirupsen/logrus"
 	oracletypes "github.com/youngjoon-lee/dhub/x/oracle/types"
 )
 
-func (e Executor) VoteForJoin(joinID uint64, option oracletypes.VoteOption, yesValue string) error {
+// maxReasonLen keeps the reason within the default max memo length of the SDK.
+const maxReasonLen = 256
+
+// VoteForJoin votes for the join. The reason of the vote is published as the memo of the tx,
+// since MsgVoteForJoin has no field for it.
+func (e Executor) VoteForJoin(joinID uint64, option oracletypes.VoteOption, yesValue, reason string) error {
 	msg := oracletypes.NewMsgVoteForJoin(joinID, option, yesValue, e.Signer().String())
 
-	res, err := e.signAndBroadcastTx(msg)
+	res, err := e.signAndBroadcastTx(truncateReason(reason), msg)
 	if err != nil {
 		return fmt.Errorf("failed to sign and broadcast tx: %w", err)
 	}
@@ -22,3 +28,15 @@ func (e Executor) VoteForJoin(joinID uint64, option oracletypes.VoteOption, yesV
 
 	return nil
 }
+
+// truncateReason cuts the reason to maxReasonLen bytes on a rune boundary, so that the memo stays valid UTF-8.
+func truncateReason(reason string) string {
+	if len(reason) <= maxReasonLen {
+		return reason
+	}
+	end := maxReasonLen
+	for end > 0 && !utf8.RuneStart(reason[end]) {
+		end--
+	}
+	return reason[:end]
+}
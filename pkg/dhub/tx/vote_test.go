@@ -0,0 +1,32 @@
+package tx
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateReason(t *testing.T) {
+	short := "invalid signer ID in the report"
+	if got := truncateReason(short); got != short {
+		t.Fatalf("short reason truncated: %q", got)
+	}
+
+	ascii := strings.Repeat("a", maxReasonLen+10)
+	if got := truncateReason(ascii); got != ascii[:maxReasonLen] {
+		t.Fatalf("expected %v bytes, got %v", maxReasonLen, len(got))
+	}
+
+	// "가" is 3 bytes, so maxReasonLen (256) falls in the middle of a rune
+	multiByte := strings.Repeat("가", maxReasonLen)
+	got := truncateReason(multiByte)
+	if !utf8.ValidString(got) {
+		t.Fatalf("invalid UTF-8: %q", got)
+	}
+	if len(got) > maxReasonLen || len(got) <= maxReasonLen-utf8.UTFMax {
+		t.Fatalf("unexpected length: %v", len(got))
+	}
+	if !strings.HasPrefix(multiByte, got) {
+		t.Fatalf("not a prefix: %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package envelope
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+)
+
+const keyIDSize = 8
+
+// KeyID returns a short identifier of the public key, so that a recipient can tell which key an envelope was sealed to.
+func KeyID(pubKey *btcec.PublicKey) []byte {
+	hash := sha256.Sum256(pubKey.SerializeCompressed())
+	return hash[:keyIDSize]
+}
+
+// SealECIES encrypts data for pubKey and wraps it into an envelope.
+// The envelope header, including associatedData, is bound to the payload
+// by encrypting the SHA-256 of the header together with data.
+func SealECIES(pubKey *btcec.PublicKey, keyEpoch uint32, associatedData, data []byte) ([]byte, error) {
+	header, err := EncodeHeader(Envelope{
+		Algorithm:      AlgorithmECIESSecp256k1,
+		KeyEpoch:       keyEpoch,
+		KeyID:          KeyID(pubKey),
+		AssociatedData: associatedData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode envelope header: %w", err)
+	}
+
+	headerHash := sha256.Sum256(header)
+	payload, err := secp256k1.Encrypt(pubKey, append(headerHash[:], data...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt: %w", err)
+	}
+
+	return append(header, payload...), nil
+}
+
+// OpenECIES decodes the envelope and decrypts its payload using privKey.
+// Legacy raw ECIES ciphertexts are decrypted as they are.
+func OpenECIES(privKey *btcec.PrivateKey, bz []byte) ([]byte, Envelope, error) {
+	env, err := Decode(bz)
+	if err != nil {
+		return nil, Envelope{}, fmt.Errorf("failed to decode envelope: %w", err)
+	}
+	if env.Algorithm != AlgorithmECIESSecp256k1 {
+		return nil, env, fmt.Errorf("unexpected algorithm: %v", env.Algorithm)
+	}
+
+	if env.Version == VersionLegacy {
+		data, err := secp256k1.Decrypt(privKey, env.Payload)
+		if err != nil {
+			return nil, env, fmt.Errorf("failed to decrypt legacy ciphertext: %w", err)
+		}
+		return data, env, nil
+	}
+
+	if len(env.KeyID) > 0 && !bytes.Equal(env.KeyID, KeyID(privKey.PubKey())) {
+		return nil, env, fmt.Errorf("envelope was sealed to another key: %x", env.KeyID)
+	}
+
+	plaintext, err := secp256k1.Decrypt(privKey, env.Payload)
+	if err != nil {
+		return nil, env, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	if len(plaintext) < sha256.Size {
+		return nil, env, fmt.Errorf("plaintext too short")
+	}
+
+	headerHash := sha256.Sum256(bz[:len(bz)-len(env.Payload)])
+	if !bytes.Equal(plaintext[:sha256.Size], headerHash[:]) {
+		return nil, env, fmt.Errorf("envelope header was tampered")
+	}
+
+	return plaintext[sha256.Size:], env, nil
+}
@@ -0,0 +1,169 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// An envelope is a self-describing header in front of a ciphertext, so that algorithms and keys can be rotated
+// without ambiguity.
+//
+//	magic(4) | version(1) | algorithm(1) | keyEpoch(4) | keyIDLen(1) | keyID | adLen(2) | ad | payload
+//
+// Ciphertexts produced before envelopes were introduced are raw ECIES bytes without any header.
+// Decode treats them as version 0 with AlgorithmECIESSecp256k1.
+const (
+	VersionLegacy = uint8(0)
+	Version1      = uint8(1)
+
+	MaxKeyIDSize          = math.MaxUint8
+	MaxAssociatedDataSize = math.MaxUint16
+
+	headerFixedSize = 4 + 1 + 1 + 4
+)
+
+var (
+	magic                  = []byte("DOEV")
+	legacyECIESCurveParams = []byte{0x02, 0xca, 0x00, 0x20}
+)
+
+type Algorithm uint8
+
+const (
+	AlgorithmUnknown Algorithm = iota
+	// AlgorithmECIESSecp256k1 is the btcec ECIES used by secp256k1.Encrypt.
+	AlgorithmECIESSecp256k1
+	// AlgorithmStreamSecp256k1AESGCM is the chunked format produced by secp256k1.EncryptStream.
+	AlgorithmStreamSecp256k1AESGCM
+)
+
+func (a Algorithm) String() string {
+	switch a {
+	case AlgorithmECIESSecp256k1:
+		return "ecies-secp256k1"
+	case AlgorithmStreamSecp256k1AESGCM:
+		return "stream-secp256k1-aes256gcm"
+	default:
+		return fmt.Sprintf("unknown(%d)", uint8(a))
+	}
+}
+
+type Envelope struct {
+	Version        uint8
+	Algorithm      Algorithm
+	KeyEpoch       uint32
+	KeyID          []byte
+	AssociatedData []byte
+	Payload        []byte
+}
+
+// Encode serializes the envelope. The version is always set to Version1.
+func Encode(env Envelope) ([]byte, error) {
+	header, err := EncodeHeader(env)
+	if err != nil {
+		return nil, err
+	}
+	return append(header, env.Payload...), nil
+}
+
+// EncodeHeader serializes the envelope without its payload,
+// for callers who write a large payload directly after the header.
+func EncodeHeader(env Envelope) ([]byte, error) {
+	if env.Algorithm == AlgorithmUnknown {
+		return nil, fmt.Errorf("algorithm not specified")
+	}
+	if len(env.KeyID) > MaxKeyIDSize {
+		return nil, fmt.Errorf("key ID too long: %v", len(env.KeyID))
+	}
+	if len(env.AssociatedData) > MaxAssociatedDataSize {
+		return nil, fmt.Errorf("associated data too long: %v", len(env.AssociatedData))
+	}
+
+	header := make([]byte, headerFixedSize, headerFixedSize+1+len(env.KeyID)+2+len(env.AssociatedData)+len(env.Payload))
+	copy(header, magic)
+	header[4] = Version1
+	header[5] = byte(env.Algorithm)
+	binary.BigEndian.PutUint32(header[6:10], env.KeyEpoch)
+
+	header = append(header, byte(len(env.KeyID)))
+	header = append(header, env.KeyID...)
+
+	adLen := make([]byte, 2)
+	binary.BigEndian.PutUint16(adLen, uint16(len(env.AssociatedData)))
+	header = append(header, adLen...)
+	header = append(header, env.AssociatedData...)
+
+	return header, nil
+}
+
+// Decode parses an envelope. Bytes that don't start with the envelope magic are returned as a legacy envelope
+// whose payload is the whole input.
+func Decode(bz []byte) (Envelope, error) {
+	if !bytes.HasPrefix(bz, magic) {
+		return legacy(bz), nil
+	}
+
+	env, err := decode(bz)
+	if err != nil {
+		// A raw ECIES ciphertext starts with a random IV, so it can begin with the magic by chance.
+		if looksLikeLegacyECIES(bz) {
+			return legacy(bz), nil
+		}
+		return Envelope{}, err
+	}
+	return env, nil
+}
+
+func decode(bz []byte) (Envelope, error) {
+	if len(bz) < headerFixedSize+1 {
+		return Envelope{}, fmt.Errorf("envelope too short: %v", len(bz))
+	}
+
+	env := Envelope{
+		Version:   bz[4],
+		Algorithm: Algorithm(bz[5]),
+		KeyEpoch:  binary.BigEndian.Uint32(bz[6:10]),
+	}
+	if env.Version != Version1 {
+		return Envelope{}, fmt.Errorf("unsupported envelope version: %v", env.Version)
+	}
+	if env.Algorithm == AlgorithmUnknown || env.Algorithm > AlgorithmStreamSecp256k1AESGCM {
+		return Envelope{}, fmt.Errorf("unsupported algorithm: %v", env.Algorithm)
+	}
+
+	rest := bz[headerFixedSize:]
+
+	keyIDLen := int(rest[0])
+	rest = rest[1:]
+	if len(rest) < keyIDLen+2 {
+		return Envelope{}, fmt.Errorf("envelope truncated in key ID")
+	}
+	env.KeyID = rest[:keyIDLen]
+	rest = rest[keyIDLen:]
+
+	adLen := int(binary.BigEndian.Uint16(rest[:2]))
+	rest = rest[2:]
+	if len(rest) < adLen {
+		return Envelope{}, fmt.Errorf("envelope truncated in associated data")
+	}
+	env.AssociatedData = rest[:adLen]
+	env.Payload = rest[adLen:]
+
+	return env, nil
+}
+
+func legacy(bz []byte) Envelope {
+	return Envelope{
+		Version:   VersionLegacy,
+		Algorithm: AlgorithmECIESSecp256k1,
+		Payload:   bz,
+	}
+}
+
+// looksLikeLegacyECIES checks the layout of btcec ECIES:
+// IV(16) | curve(2) | xLen(2) | X(32) | yLen(2) | Y(32) | ciphertext(16n) | HMAC(32).
+func looksLikeLegacyECIES(bz []byte) bool {
+	return len(bz) >= 16+70+16+32 && bytes.Equal(bz[16:20], legacyECIESCurveParams)
+}
@@ -0,0 +1,82 @@
+package envelope
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+)
+
+// NewStreamWriter writes an envelope header to w and returns a writer which encrypts the payload for pubKey
+// in the stream format. The envelope header is bound to every record of the stream.
+// The returned writer must be closed to complete the stream.
+func NewStreamWriter(w io.Writer, pubKey *btcec.PublicKey, keyEpoch uint32, associatedData []byte) (*secp256k1.EncryptWriter, error) {
+	header, err := EncodeHeader(Envelope{
+		Algorithm:      AlgorithmStreamSecp256k1AESGCM,
+		KeyEpoch:       keyEpoch,
+		KeyID:          KeyID(pubKey),
+		AssociatedData: associatedData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode envelope header: %w", err)
+	}
+
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write envelope header: %w", err)
+	}
+
+	return secp256k1.NewEncryptWriterWithContext(w, pubKey, secp256k1.DefaultChunkSize, header)
+}
+
+// NewStreamReader reads an envelope header from r and returns a reader which decrypts the stream payload using privKey.
+func NewStreamReader(r io.Reader, privKey *btcec.PrivateKey) (*secp256k1.DecryptReader, Envelope, error) {
+	env, header, err := ReadHeader(r)
+	if err != nil {
+		return nil, Envelope{}, fmt.Errorf("failed to read envelope header: %w", err)
+	}
+	if env.Algorithm != AlgorithmStreamSecp256k1AESGCM {
+		return nil, env, fmt.Errorf("unexpected algorithm: %v", env.Algorithm)
+	}
+	if len(env.KeyID) > 0 && !bytes.Equal(env.KeyID, KeyID(privKey.PubKey())) {
+		return nil, env, fmt.Errorf("envelope was sealed to another key: %x", env.KeyID)
+	}
+
+	dr, err := secp256k1.NewDecryptReaderWithContext(r, privKey, header)
+	if err != nil {
+		return nil, env, err
+	}
+	return dr, env, nil
+}
+
+// ReadHeader reads an envelope header from r, leaving r at the beginning of the payload.
+// It returns the raw header bytes as well. Legacy ciphertexts are not supported because they have no header.
+func ReadHeader(r io.Reader) (Envelope, []byte, error) {
+	header := make([]byte, headerFixedSize+1)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return Envelope{}, nil, err
+	}
+	if !bytes.HasPrefix(header, magic) {
+		return Envelope{}, nil, fmt.Errorf("invalid magic")
+	}
+
+	keyID := make([]byte, int(header[headerFixedSize])+2)
+	if _, err := io.ReadFull(r, keyID); err != nil {
+		return Envelope{}, nil, err
+	}
+	header = append(header, keyID...)
+
+	ad := make([]byte, binary.BigEndian.Uint16(keyID[len(keyID)-2:]))
+	if _, err := io.ReadFull(r, ad); err != nil {
+		return Envelope{}, nil, err
+	}
+	header = append(header, ad...)
+
+	env, err := decode(header)
+	if err != nil {
+		return Envelope{}, nil, err
+	}
+	return env, header, nil
+}
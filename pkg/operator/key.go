@@ -0,0 +1,197 @@
+package operator
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	cosmossecp256k1 "github.com/cosmos/cosmos-sdk/crypto/keys/secp256k1"
+	cryptotypes "github.com/cosmos/cosmos-sdk/crypto/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/config"
+	"github.com/youngjoon-lee/doracle-poc/pkg/dhub/tx"
+	"github.com/youngjoon-lee/doracle-poc/pkg/secp256k1"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+	"golang.org/x/term"
+)
+
+// keyringAppName is the service name of the keyring, which is the same as the one used by the dhub CLI.
+const keyringAppName = "dhub"
+
+// LoadSigner returns the signer of the operator account from the source specified in the config.
+// Keys in the keyring and the remote signer are never loaded into memory.
+func LoadSigner(cfg config.OperatorConfig) (tx.Signer, error) {
+	switch cfg.Source {
+	case config.OperatorSourceKeyring:
+		kr, err := keyring.New(keyringAppName, cfg.KeyringBackend, cfg.KeyringDir, os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open keyring: %w", err)
+		}
+		return tx.NewKeyringSigner(kr, cfg.KeyName)
+	case config.OperatorSourceRemote:
+		return tx.NewRemoteSigner(cfg.RemoteSignerAddr)
+	default:
+		privKey, _, err := LoadKey(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return tx.NewPrivKeySigner(privKey), nil
+	}
+}
+
+// LoadKey loads the operator key from the source specified in the config.
+func LoadKey(cfg config.OperatorConfig) (cryptotypes.PrivKey, sdk.AccAddress, error) {
+	switch cfg.Source {
+	case config.OperatorSourceMnemonic:
+		log.Warn("the operator mnemonic is given in plaintext. consider using another operator key source")
+		return secp256k1.PrivateKeyFromMnemonicWithParams(cfg.Mnemonic, hdParams(cfg))
+	case config.OperatorSourceEnv:
+		return fromEnv(cfg.MnemonicEnv, hdParams(cfg))
+	case config.OperatorSourceFile:
+		return fromFile(cfg.MnemonicFile, hdParams(cfg))
+	case config.OperatorSourcePrompt:
+		return fromPrompt(hdParams(cfg))
+	case config.OperatorSourceKeyring:
+		return fromKeyring(cfg.KeyringBackend, cfg.KeyringDir, cfg.KeyName)
+	case config.OperatorSourceSealed:
+		return fromSealed(cfg.SealedFile)
+	case config.OperatorSourceRemote:
+		return nil, nil, fmt.Errorf("the key of the remote signer cannot be loaded")
+	default:
+		return nil, nil, fmt.Errorf("unknown operator key source: %v", cfg.Source)
+	}
+}
+
+// hdParams returns the HD params, which are applied only to the sources providing a mnemonic.
+func hdParams(cfg config.OperatorConfig) secp256k1.HDParams {
+	return secp256k1.HDParams{
+		Account:      cfg.HDAccount,
+		AddressIndex: cfg.HDAddressIndex,
+		CoinType:     cfg.HDCoinType,
+		Passphrase:   cfg.BIP39Passphrase,
+	}
+}
+
+// UsesMnemonic returns whether the operator key is derived from a mnemonic with the HD params.
+func UsesMnemonic(cfg config.OperatorConfig) bool {
+	switch cfg.Source {
+	case config.OperatorSourceMnemonic, config.OperatorSourceEnv, config.OperatorSourceFile, config.OperatorSourcePrompt:
+		return true
+	default:
+		return false
+	}
+}
+
+// HDPath returns the HD path of the operator key derived from a mnemonic.
+func HDPath(cfg config.OperatorConfig) string {
+	return hdParams(cfg).Path()
+}
+
+func fromEnv(name string, params secp256k1.HDParams) (cryptotypes.PrivKey, sdk.AccAddress, error) {
+	mnemonic, ok := os.LookupEnv(name)
+	if !ok {
+		return nil, nil, fmt.Errorf("env var %v not set", name)
+	}
+	// not to be inherited by child processes
+	if err := os.Unsetenv(name); err != nil {
+		return nil, nil, fmt.Errorf("failed to unset %v: %w", name, err)
+	}
+	return secp256k1.PrivateKeyFromMnemonicWithParams(strings.TrimSpace(mnemonic), params)
+}
+
+// fromFile reads the mnemonic from the file which must not be accessible by anyone but the owner.
+func fromFile(path string, params secp256k1.HDParams) (cryptotypes.PrivKey, sdk.AccAddress, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to stat %v: %w", path, err)
+	}
+	if perm := info.Mode().Perm(); perm&0077 != 0 {
+		return nil, nil, fmt.Errorf("%v must not be accessible by group or others (mode %04o): run chmod 600 %v", path, perm, path)
+	}
+
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read %v: %w", path, err)
+	}
+	return secp256k1.PrivateKeyFromMnemonicWithParams(strings.TrimSpace(string(bz)), params)
+}
+
+// fromPrompt reads the mnemonic from the terminal without echoing it.
+// If stdin is not a terminal, a line is read from stdin, so that the mnemonic can be piped.
+func fromPrompt(params secp256k1.HDParams) (cryptotypes.PrivKey, sdk.AccAddress, error) {
+	var mnemonic string
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Enter the operator mnemonic: ")
+		bz, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read mnemonic: %w", err)
+		}
+		mnemonic = string(bz)
+	} else {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return nil, nil, fmt.Errorf("failed to read mnemonic from stdin: %w", err)
+		}
+		mnemonic = line
+	}
+
+	return secp256k1.PrivateKeyFromMnemonicWithParams(strings.TrimSpace(mnemonic), params)
+}
+
+// fromKeyring exports the key from the Cosmos SDK keyring, which may prompt the keyring passphrase.
+func fromKeyring(backend, dir, name string) (cryptotypes.PrivKey, sdk.AccAddress, error) {
+	kr, err := keyring.New(keyringAppName, backend, dir, os.Stdin)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open keyring: %w", err)
+	}
+
+	privKeyHex, err := keyring.NewUnsafe(kr).UnsafeExportPrivKeyHex(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to export key %v from keyring: %w", name, err)
+	}
+	privKeyBytes, err := hex.DecodeString(privKeyHex)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode key %v: %w", name, err)
+	}
+
+	privKey := &cosmossecp256k1.PrivKey{Key: privKeyBytes}
+	return privKey, sdk.AccAddress(privKey.PubKey().Address()), nil
+}
+
+// SealKey loads the operator key from the source specified in the config, and seals it to the sealed file,
+// so that the key can be loaded by the "sealed" source thereafter without the host providing it again.
+func SealKey(cfg config.OperatorConfig) (sdk.AccAddress, error) {
+	if cfg.Source == config.OperatorSourceSealed {
+		return nil, fmt.Errorf("the operator key is already sealed")
+	}
+	if _, err := os.Stat(cfg.SealedFile); err == nil {
+		return nil, fmt.Errorf("%v already exists", cfg.SealedFile)
+	}
+
+	privKey, addr, err := LoadKey(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := sgx.SealToFile(privKey.Bytes(), cfg.SealedFile); err != nil {
+		return nil, fmt.Errorf("failed to seal operator key: %w", err)
+	}
+	return addr, nil
+}
+
+func fromSealed(path string) (cryptotypes.PrivKey, sdk.AccAddress, error) {
+	privKeyBytes, err := sgx.UnsealFromFile(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to unseal operator key: %w", err)
+	}
+
+	privKey := &cosmossecp256k1.PrivKey{Key: privKeyBytes}
+	return privKey, sdk.AccAddress(privKey.PubKey().Address()), nil
+}
@@ -0,0 +1,68 @@
+package oraclesig
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// Every output of oracles is signed by the oracle key, which exists only in the enclave,
+// so that consumers know the output was produced by a genuine enclave, not forged by the operator.
+//
+// The signing bytes are:
+//
+//	prefix | 0x00 | len(domain)(2) | domain | len(chainID)(2) | chainID | SHA-256(payload)
+//
+// The domain separates different kinds of outputs, and the chain ID prevents replays across chains.
+// The signature is a DER-encoded ECDSA signature over SHA-256(signing bytes).
+const prefix = "doracle-poc/oraclesig/v1"
+
+const (
+	DomainValidationReport = "validation_report"
+	// DomainDataSubmission is for data submitted directly to oracles, which is signed by data sellers, not by oracles.
+	DomainDataSubmission = "data_submission"
+	// DomainValidationRequest is for requests of validation results, which are signed by data sellers.
+	DomainValidationRequest = "validation_request"
+)
+
+func SignBytes(domain, chainID string, payload []byte) []byte {
+	payloadHash := sha256.Sum256(payload)
+
+	bz := make([]byte, 0, len(prefix)+1+2+len(domain)+2+len(chainID)+len(payloadHash))
+	bz = append(bz, prefix...)
+	bz = append(bz, 0)
+	bz = appendLengthPrefixed(bz, domain)
+	bz = appendLengthPrefixed(bz, chainID)
+	return append(bz, payloadHash[:]...)
+}
+
+func Sign(oraclePrivKey *btcec.PrivateKey, domain, chainID string, payload []byte) ([]byte, error) {
+	hash := sha256.Sum256(SignBytes(domain, chainID, payload))
+	sig, err := oraclePrivKey.Sign(hash[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign: %w", err)
+	}
+	return sig.Serialize(), nil
+}
+
+func Verify(oraclePubKey *btcec.PublicKey, domain, chainID string, payload, sigBytes []byte) error {
+	sig, err := btcec.ParseDERSignature(sigBytes, btcec.S256())
+	if err != nil {
+		return fmt.Errorf("invalid signature: %w", err)
+	}
+
+	hash := sha256.Sum256(SignBytes(domain, chainID, payload))
+	if !sig.Verify(hash[:], oraclePubKey) {
+		return fmt.Errorf("signature verification failed")
+	}
+	return nil
+}
+
+func appendLengthPrefixed(bz []byte, s string) []byte {
+	length := make([]byte, 2)
+	binary.BigEndian.PutUint16(length, uint16(len(s)))
+	bz = append(bz, length...)
+	return append(bz, s...)
+}
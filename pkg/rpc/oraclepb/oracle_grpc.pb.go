@@ -0,0 +1,283 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+
+package oraclepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// OracleServiceClient is the client API for OracleService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type OracleServiceClient interface {
+	// GetInfo returns the status of the running oracle.
+	GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error)
+	// GetAttestation returns a fresh SGX report bound to the nonce and the oracle public key.
+	GetAttestation(ctx context.Context, in *GetAttestationRequest, opts ...grpc.CallOption) (*GetAttestationResponse, error)
+	// SubmitData submits a ciphertext for an on-chain request directly to the oracle.
+	SubmitData(ctx context.Context, in *SubmitDataRequest, opts ...grpc.CallOption) (*SubmitDataResponse, error)
+	// GetValidationResult validates the data submitted for a request, and returns the report signed by the oracle key.
+	GetValidationResult(ctx context.Context, in *GetValidationResultRequest, opts ...grpc.CallOption) (*GetValidationResultResponse, error)
+	// WatchEvents streams the chain events processed by the oracle.
+	WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (OracleService_WatchEventsClient, error)
+}
+
+type oracleServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewOracleServiceClient(cc grpc.ClientConnInterface) OracleServiceClient {
+	return &oracleServiceClient{cc}
+}
+
+func (c *oracleServiceClient) GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error) {
+	out := new(GetInfoResponse)
+	err := c.cc.Invoke(ctx, "/doracle.oracle.v1.OracleService/GetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oracleServiceClient) GetAttestation(ctx context.Context, in *GetAttestationRequest, opts ...grpc.CallOption) (*GetAttestationResponse, error) {
+	out := new(GetAttestationResponse)
+	err := c.cc.Invoke(ctx, "/doracle.oracle.v1.OracleService/GetAttestation", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oracleServiceClient) SubmitData(ctx context.Context, in *SubmitDataRequest, opts ...grpc.CallOption) (*SubmitDataResponse, error) {
+	out := new(SubmitDataResponse)
+	err := c.cc.Invoke(ctx, "/doracle.oracle.v1.OracleService/SubmitData", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oracleServiceClient) GetValidationResult(ctx context.Context, in *GetValidationResultRequest, opts ...grpc.CallOption) (*GetValidationResultResponse, error) {
+	out := new(GetValidationResultResponse)
+	err := c.cc.Invoke(ctx, "/doracle.oracle.v1.OracleService/GetValidationResult", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *oracleServiceClient) WatchEvents(ctx context.Context, in *WatchEventsRequest, opts ...grpc.CallOption) (OracleService_WatchEventsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &OracleService_ServiceDesc.Streams[0], "/doracle.oracle.v1.OracleService/WatchEvents", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &oracleServiceWatchEventsClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type OracleService_WatchEventsClient interface {
+	Recv() (*Event, error)
+	grpc.ClientStream
+}
+
+type oracleServiceWatchEventsClient struct {
+	grpc.ClientStream
+}
+
+func (x *oracleServiceWatchEventsClient) Recv() (*Event, error) {
+	m := new(Event)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// OracleServiceServer is the server API for OracleService service.
+// All implementations must embed UnimplementedOracleServiceServer
+// for forward compatibility
+type OracleServiceServer interface {
+	// GetInfo returns the status of the running oracle.
+	GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error)
+	// GetAttestation returns a fresh SGX report bound to the nonce and the oracle public key.
+	GetAttestation(context.Context, *GetAttestationRequest) (*GetAttestationResponse, error)
+	// SubmitData submits a ciphertext for an on-chain request directly to the oracle.
+	SubmitData(context.Context, *SubmitDataRequest) (*SubmitDataResponse, error)
+	// GetValidationResult validates the data submitted for a request, and returns the report signed by the oracle key.
+	GetValidationResult(context.Context, *GetValidationResultRequest) (*GetValidationResultResponse, error)
+	// WatchEvents streams the chain events processed by the oracle.
+	WatchEvents(*WatchEventsRequest, OracleService_WatchEventsServer) error
+	mustEmbedUnimplementedOracleServiceServer()
+}
+
+// UnimplementedOracleServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedOracleServiceServer struct {
+}
+
+func (UnimplementedOracleServiceServer) GetInfo(context.Context, *GetInfoRequest) (*GetInfoResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInfo not implemented")
+}
+func (UnimplementedOracleServiceServer) GetAttestation(context.Context, *GetAttestationRequest) (*GetAttestationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAttestation not implemented")
+}
+func (UnimplementedOracleServiceServer) SubmitData(context.Context, *SubmitDataRequest) (*SubmitDataResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SubmitData not implemented")
+}
+func (UnimplementedOracleServiceServer) GetValidationResult(context.Context, *GetValidationResultRequest) (*GetValidationResultResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetValidationResult not implemented")
+}
+func (UnimplementedOracleServiceServer) WatchEvents(*WatchEventsRequest, OracleService_WatchEventsServer) error {
+	return status.Errorf(codes.Unimplemented, "method WatchEvents not implemented")
+}
+func (UnimplementedOracleServiceServer) mustEmbedUnimplementedOracleServiceServer() {}
+
+// UnsafeOracleServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to OracleServiceServer will
+// result in compilation errors.
+type UnsafeOracleServiceServer interface {
+	mustEmbedUnimplementedOracleServiceServer()
+}
+
+func RegisterOracleServiceServer(s grpc.ServiceRegistrar, srv OracleServiceServer) {
+	s.RegisterService(&OracleService_ServiceDesc, srv)
+}
+
+func _OracleService_GetInfo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OracleServiceServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/doracle.oracle.v1.OracleService/GetInfo",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OracleServiceServer).GetInfo(ctx, req.(*GetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OracleService_GetAttestation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAttestationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OracleServiceServer).GetAttestation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/doracle.oracle.v1.OracleService/GetAttestation",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OracleServiceServer).GetAttestation(ctx, req.(*GetAttestationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OracleService_SubmitData_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SubmitDataRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OracleServiceServer).SubmitData(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/doracle.oracle.v1.OracleService/SubmitData",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OracleServiceServer).SubmitData(ctx, req.(*SubmitDataRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OracleService_GetValidationResult_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetValidationResultRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(OracleServiceServer).GetValidationResult(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/doracle.oracle.v1.OracleService/GetValidationResult",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(OracleServiceServer).GetValidationResult(ctx, req.(*GetValidationResultRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _OracleService_WatchEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(OracleServiceServer).WatchEvents(m, &oracleServiceWatchEventsServer{stream})
+}
+
+type OracleService_WatchEventsServer interface {
+	Send(*Event) error
+	grpc.ServerStream
+}
+
+type oracleServiceWatchEventsServer struct {
+	grpc.ServerStream
+}
+
+func (x *oracleServiceWatchEventsServer) Send(m *Event) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// OracleService_ServiceDesc is the grpc.ServiceDesc for OracleService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var OracleService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "doracle.oracle.v1.OracleService",
+	HandlerType: (*OracleServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetInfo",
+			Handler:    _OracleService_GetInfo_Handler,
+		},
+		{
+			MethodName: "GetAttestation",
+			Handler:    _OracleService_GetAttestation_Handler,
+		},
+		{
+			MethodName: "SubmitData",
+			Handler:    _OracleService_SubmitData_Handler,
+		},
+		{
+			MethodName: "GetValidationResult",
+			Handler:    _OracleService_GetValidationResult_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchEvents",
+			Handler:       _OracleService_WatchEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "doracle/oracle/v1/oracle.proto",
+}
@@ -0,0 +1,83 @@
+package rpc
+
+//go:generate protoc -I ../../proto --go_out=../.. --go_opt=module=github.com/youngjoon-lee/doracle-poc --go-grpc_out=../.. --go-grpc_opt=module=github.com/youngjoon-lee/doracle-poc doracle/oracle/v1/oracle.proto
+
+import (
+	"fmt"
+	"net"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/rpc/oraclepb"
+	"github.com/youngjoon-lee/doracle-poc/pkg/sgx"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Server serves the OracleService over gRPC for services which integrate with the oracle without the HTTP API.
+type Server struct {
+	oraclepb.UnimplementedOracleServiceServer
+
+	app        *app.App
+	identity   *sgx.Identity
+	listenAddr string
+	grpcServer *grpc.Server
+	closing    chan struct{}
+}
+
+// NewServer creates a server. If attestedTLS is true, the server is served over TLS
+// with a certificate bound to a SGX report (see sgx.CreateAttestedServerTLSConfig).
+func NewServer(app *app.App, listenAddr string, attestedTLS bool) (*Server, error) {
+	s := &Server{
+		app:        app,
+		listenAddr: listenAddr,
+		closing:    make(chan struct{}),
+	}
+
+	var opts []grpc.ServerOption
+	if attestedTLS {
+		tlsConfig, err := sgx.CreateAttestedServerTLSConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to create TLS config: %w", err)
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+	if dataCache := app.DataCache(); dataCache != nil {
+		// leave room for the other fields of SubmitDataRequest
+		opts = append(opts, grpc.MaxRecvMsgSize(dataCache.MaxEntrySize()+4096))
+	}
+
+	identity, err := sgx.SelfIdentity()
+	if err != nil {
+		log.Warnf("failed to get enclave identity: %v", err)
+	} else {
+		s.identity = &identity
+	}
+
+	s.grpcServer = grpc.NewServer(opts...)
+	oraclepb.RegisterOracleServiceServer(s.grpcServer, s)
+	return s, nil
+}
+
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.listenAddr)
+	if err != nil {
+		return fmt.Errorf("failed to listen %v: %w", s.listenAddr, err)
+	}
+
+	go func() {
+		if err := s.grpcServer.Serve(listener); err != nil {
+			log.Errorf("gRPC server stopped: %v", err)
+		}
+	}()
+
+	log.Infof("gRPC server listening on %v", s.listenAddr)
+	return nil
+}
+
+// Close stops the server gracefully. WatchEvents streams are ended first, since they never finish by themselves.
+func (s *Server) Close() {
+	log.Info("stopping gRPC server...")
+	close(s.closing)
+	s.grpcServer.GracefulStop()
+}
@@ -0,0 +1,142 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/youngjoon-lee/doracle-poc/pkg/app"
+	"github.com/youngjoon-lee/doracle-poc/pkg/datacache"
+	"github.com/youngjoon-lee/doracle-poc/pkg/rpc/oraclepb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) GetInfo(context.Context, *oraclepb.GetInfoRequest) (*oraclepb.GetInfoResponse, error) {
+	txExecutor := s.app.TxExecutor()
+	subscriber := s.app.Subscriber()
+
+	res := &oraclepb.GetInfoResponse{
+		ChainId:         txExecutor.ChainID(),
+		OperatorAddress: txExecutor.Signer().String(),
+		LastHeight:      subscriber.LastHeight(),
+		PendingTxs:      txExecutor.PendingTxs(),
+		Observer:        s.app.Observer(),
+	}
+	if oraclePrivKey := s.app.OraclePrivKey(); oraclePrivKey != nil {
+		res.OraclePubKey = oraclePrivKey.PubKey().SerializeCompressed()
+	}
+	if s.identity != nil {
+		res.Enclave = &oraclepb.EnclaveIdentity{
+			SignerId:        s.identity.SignerID,
+			UniqueId:        s.identity.UniqueID,
+			ProductId:       uint32(s.identity.ProductID),
+			SecurityVersion: uint32(s.identity.SecurityVersion),
+			Debug:           s.identity.Debug,
+		}
+	}
+	for _, sub := range subscriber.Subscriptions() {
+		res.Subscriptions = append(res.Subscriptions, &oraclepb.Subscription{Name: sub.Name, Query: sub.Query})
+	}
+	return res, nil
+}
+
+func (s *Server) GetAttestation(_ context.Context, req *oraclepb.GetAttestationRequest) (*oraclepb.GetAttestationResponse, error) {
+	oraclePubKey, report, err := s.app.Attest(req.Nonce)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &oraclepb.GetAttestationResponse{
+		OraclePubKey: oraclePubKey.SerializeCompressed(),
+		Report:       report,
+	}, nil
+}
+
+func (s *Server) SubmitData(_ context.Context, req *oraclepb.SubmitDataRequest) (*oraclepb.SubmitDataResponse, error) {
+	seller, expiresAt, err := s.app.SubmitData(req.RequestId, req.Timestamp, req.SellerPubKey, req.Signature, req.Ciphertext)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &oraclepb.SubmitDataResponse{
+		RequestId: req.RequestId,
+		Seller:    seller,
+		ExpiresAt: timestamppb.New(expiresAt),
+	}, nil
+}
+
+func (s *Server) GetValidationResult(_ context.Context, req *oraclepb.GetValidationResultRequest) (*oraclepb.GetValidationResultResponse, error) {
+	signedReport, err := s.app.ValidateSubmission(req.RequestId, req.Timestamp, req.SellerPubKey, req.Signature)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &oraclepb.GetValidationResultResponse{
+		ChainId:   signedReport.ChainID,
+		Report:    signedReport.Report,
+		Signature: signedReport.Signature,
+	}, nil
+}
+
+func (s *Server) WatchEvents(req *oraclepb.WatchEventsRequest, stream oraclepb.OracleService_WatchEventsServer) error {
+	filter := make(map[string]bool, len(req.Subscriptions))
+	for _, name := range req.Subscriptions {
+		filter[name] = true
+	}
+
+	eventCh, stop := s.app.Subscriber().Watch()
+	defer stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.closing:
+			return nil
+		case ev := <-eventCh:
+			if len(filter) > 0 && !filter[ev.Subscription] {
+				continue
+			}
+
+			msg := &oraclepb.Event{
+				Subscription: ev.Subscription,
+				Height:       ev.Height,
+				Events:       make(map[string]*oraclepb.EventAttributeValues, len(ev.Events)),
+			}
+			for key, values := range ev.Events {
+				msg.Events[key] = &oraclepb.EventAttributeValues{Values: values}
+			}
+			if ev.Err != nil {
+				msg.Error = ev.Err.Error()
+			}
+
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toStatus maps errors returned by the app to gRPC status codes.
+func toStatus(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, app.ErrInvalidRequest):
+		code = codes.InvalidArgument
+	case errors.Is(err, app.ErrUnauthenticated):
+		code = codes.Unauthenticated
+	case errors.Is(err, app.ErrRateLimited):
+		code = codes.ResourceExhausted
+	case errors.Is(err, app.ErrOracleKeyNotLoaded), errors.Is(err, app.ErrDataSubmissionDisabled), errors.Is(err, app.ErrValidationDisabled):
+		code = codes.Unavailable
+	case errors.Is(err, datacache.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, datacache.ErrExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, datacache.ErrTooLarge):
+		code = codes.InvalidArgument
+	case errors.Is(err, datacache.ErrCacheFull), errors.Is(err, datacache.ErrQuota):
+		code = codes.ResourceExhausted
+	default:
+		code = codes.Internal
+	}
+	return status.Error(code, err.Error())
+}
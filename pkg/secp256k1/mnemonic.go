@@ -15,16 +15,41 @@ import (
 const (
 	defaultAccount      = 0
 	defaultAddressIndex = 0
-	coinType            = uint32(118)
+	defaultCoinType     = uint32(118)
 )
 
+// HDParams specifies the BIP44 path m/44'/<coin type>'/<account>'/0/<address index> and the BIP39 passphrase
+// used to derive a key from a mnemonic.
+type HDParams struct {
+	Account      uint32
+	AddressIndex uint32
+	CoinType     uint32
+	Passphrase   string
+}
+
+func DefaultHDParams() HDParams {
+	return HDParams{
+		Account:      defaultAccount,
+		AddressIndex: defaultAddressIndex,
+		CoinType:     defaultCoinType,
+	}
+}
+
+func (p HDParams) Path() string {
+	return hd.NewFundraiserParams(p.Account, p.CoinType, p.AddressIndex).String()
+}
+
 func PrivateKeyFromMnemonic(mnemonic string) (cryptotypes.PrivKey, sdk.AccAddress, error) {
+	return PrivateKeyFromMnemonicWithParams(mnemonic, DefaultHDParams())
+}
+
+func PrivateKeyFromMnemonicWithParams(mnemonic string, params HDParams) (cryptotypes.PrivKey, sdk.AccAddress, error) {
 	if !bip39.IsMnemonicValid(mnemonic) {
 		return nil, nil, fmt.Errorf("invalid mnemonic")
 	}
 
-	hdPath := hd.NewFundraiserParams(defaultAccount, coinType, defaultAddressIndex).String()
-	master, ch := hd.ComputeMastersFromSeed(bip39.NewSeed(mnemonic, ""))
+	hdPath := params.Path()
+	master, ch := hd.ComputeMastersFromSeed(bip39.NewSeed(mnemonic, params.Passphrase))
 
 	privKeyBytes, err := hd.DerivePrivateKeyForPath(master, ch, hdPath)
 	if err != nil {
@@ -0,0 +1,469 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec"
+)
+
+// The stream format is a hybrid scheme for payloads that are too large to be held in the enclave heap.
+// A random AES-256 data key is wrapped with ECIES for the recipient, and the payload is split into
+// chunks that are sealed independently with AES-GCM. The last record is a manifest carrying the chunk count,
+// the plaintext size and the SHA-256 digest of the plaintext, so that truncation and reordering are detected.
+//
+//	header:   magic(4) | version(1) | chunkSize(4) | wrappedKeyLen(2) | wrappedKey
+//	record:   type(1) | sealedLen(4) | sealed
+//
+// Each record is sealed with the nonce derived from its index and authenticated with
+// SHA-256(context | header) | type | index as associated data, where the context is optionally given by the caller.
+const (
+	DefaultChunkSize = 1 << 20
+	MaxChunkSize     = 16 << 20
+
+	streamVersion      = 1
+	streamDataKeySize  = 32
+	streamManifestSize = 8 + 8 + sha256.Size
+
+	recordTypeChunk    = byte(1)
+	recordTypeManifest = byte(2)
+)
+
+var streamMagic = []byte("DOST")
+
+var ErrStreamTruncated = errors.New("stream truncated before manifest")
+
+// StreamManifest describes the plaintext of a stream. It is authenticated as the final record of the stream.
+type StreamManifest struct {
+	ChunkCount uint64
+	Size       uint64
+	Digest     [sha256.Size]byte
+}
+
+func (m StreamManifest) marshal() []byte {
+	bz := make([]byte, streamManifestSize)
+	binary.BigEndian.PutUint64(bz[0:8], m.ChunkCount)
+	binary.BigEndian.PutUint64(bz[8:16], m.Size)
+	copy(bz[16:], m.Digest[:])
+	return bz
+}
+
+func unmarshalStreamManifest(bz []byte) (StreamManifest, error) {
+	if len(bz) != streamManifestSize {
+		return StreamManifest{}, fmt.Errorf("invalid manifest size: %v", len(bz))
+	}
+
+	m := StreamManifest{
+		ChunkCount: binary.BigEndian.Uint64(bz[0:8]),
+		Size:       binary.BigEndian.Uint64(bz[8:16]),
+	}
+	copy(m.Digest[:], bz[16:])
+	return m, nil
+}
+
+// EncryptWriter encrypts everything written to it into the stream format.
+// Close must be called to flush the last chunk and write the manifest.
+type EncryptWriter struct {
+	w          io.Writer
+	aead       cipher.AEAD
+	headerHash [sha256.Size]byte
+	buf        []byte
+	index      uint64
+	size       uint64
+	digest     hash.Hash
+	manifest   *StreamManifest
+}
+
+// NewEncryptWriter returns an EncryptWriter with DefaultChunkSize, after writing the stream header to w.
+func NewEncryptWriter(w io.Writer, pubKey *btcec.PublicKey) (*EncryptWriter, error) {
+	return NewEncryptWriterSize(w, pubKey, DefaultChunkSize)
+}
+
+// NewEncryptWriterSize returns an EncryptWriter that splits the plaintext into chunks of chunkSize bytes.
+func NewEncryptWriterSize(w io.Writer, pubKey *btcec.PublicKey, chunkSize int) (*EncryptWriter, error) {
+	return NewEncryptWriterWithContext(w, pubKey, chunkSize, nil)
+}
+
+// NewEncryptWriterWithContext is like NewEncryptWriterSize, but every record is also bound to the context,
+// which is not written to the stream. The same context must be given to NewDecryptReaderWithContext.
+func NewEncryptWriterWithContext(w io.Writer, pubKey *btcec.PublicKey, chunkSize int, context []byte) (*EncryptWriter, error) {
+	if chunkSize <= 0 || chunkSize > MaxChunkSize {
+		return nil, fmt.Errorf("invalid chunk size: %v", chunkSize)
+	}
+
+	dataKey := make([]byte, streamDataKeySize)
+	if _, err := io.ReadFull(rand.Reader, dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	aead, err := newStreamAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := Encrypt(pubKey, dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to wrap data key: %w", err)
+	}
+
+	header := marshalStreamHeader(uint32(chunkSize), wrappedKey)
+	if _, err := w.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write stream header: %w", err)
+	}
+
+	return &EncryptWriter{
+		w:          w,
+		aead:       aead,
+		headerHash: streamHeaderHash(context, header),
+		buf:        make([]byte, 0, chunkSize),
+		digest:     sha256.New(),
+	}, nil
+}
+
+func (ew *EncryptWriter) Write(p []byte) (int, error) {
+	if ew.manifest != nil {
+		return 0, fmt.Errorf("write to closed stream")
+	}
+
+	written := 0
+	for len(p) > 0 {
+		n := copy(ew.buf[len(ew.buf):cap(ew.buf)], p)
+		ew.buf = ew.buf[:len(ew.buf)+n]
+		p = p[n:]
+		written += n
+
+		if len(ew.buf) == cap(ew.buf) {
+			if err := ew.flush(); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// Close flushes the buffered chunk and writes the manifest. It doesn't close the underlying writer.
+func (ew *EncryptWriter) Close() error {
+	if ew.manifest != nil {
+		return nil
+	}
+
+	if len(ew.buf) > 0 {
+		if err := ew.flush(); err != nil {
+			return err
+		}
+	}
+
+	manifest := StreamManifest{
+		ChunkCount: ew.index,
+		Size:       ew.size,
+	}
+	copy(manifest.Digest[:], ew.digest.Sum(nil))
+
+	if err := ew.writeRecord(recordTypeManifest, manifest.marshal()); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	ew.manifest = &manifest
+
+	return nil
+}
+
+// Manifest returns the manifest written by Close.
+func (ew *EncryptWriter) Manifest() (StreamManifest, bool) {
+	if ew.manifest == nil {
+		return StreamManifest{}, false
+	}
+	return *ew.manifest, true
+}
+
+func (ew *EncryptWriter) flush() error {
+	ew.digest.Write(ew.buf)
+	ew.size += uint64(len(ew.buf))
+
+	if err := ew.writeRecord(recordTypeChunk, ew.buf); err != nil {
+		return fmt.Errorf("failed to write chunk %v: %w", ew.index, err)
+	}
+	ew.buf = ew.buf[:0]
+	return nil
+}
+
+func (ew *EncryptWriter) writeRecord(recordType byte, plaintext []byte) error {
+	sealed := ew.aead.Seal(nil, streamNonce(ew.index), plaintext, streamAdditionalData(ew.headerHash, recordType, ew.index))
+	ew.index++
+
+	prefix := make([]byte, 5)
+	prefix[0] = recordType
+	binary.BigEndian.PutUint32(prefix[1:], uint32(len(sealed)))
+	if _, err := ew.w.Write(prefix); err != nil {
+		return err
+	}
+	_, err := ew.w.Write(sealed)
+	return err
+}
+
+// DecryptReader decrypts a stream produced by EncryptWriter.
+// Every chunk is authenticated before it is returned, and io.EOF is returned only after
+// the manifest has been verified against the chunks that were read.
+type DecryptReader struct {
+	r          io.Reader
+	aead       cipher.AEAD
+	headerHash [sha256.Size]byte
+	buf        []byte
+	pending    []byte
+	index      uint64
+	size       uint64
+	digest     hash.Hash
+	manifest   *StreamManifest
+}
+
+// NewDecryptReader reads the stream header from r and unwraps the data key using privKey.
+func NewDecryptReader(r io.Reader, privKey *btcec.PrivateKey) (*DecryptReader, error) {
+	return NewDecryptReaderWithContext(r, privKey, nil)
+}
+
+// NewDecryptReaderWithContext is like NewDecryptReader, but for streams produced by NewEncryptWriterWithContext.
+func NewDecryptReaderWithContext(r io.Reader, privKey *btcec.PrivateKey, context []byte) (*DecryptReader, error) {
+	header, chunkSize, wrappedKey, err := readStreamHeader(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream header: %w", err)
+	}
+
+	dataKey, err := Decrypt(privKey, wrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key: %w", err)
+	}
+	aead, err := newStreamAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	maxRecordSize := int(chunkSize)
+	if maxRecordSize < streamManifestSize {
+		maxRecordSize = streamManifestSize
+	}
+
+	return &DecryptReader{
+		r:          r,
+		aead:       aead,
+		headerHash: streamHeaderHash(context, header),
+		buf:        make([]byte, 0, maxRecordSize+aead.Overhead()),
+		digest:     sha256.New(),
+	}, nil
+}
+
+func (dr *DecryptReader) Read(p []byte) (int, error) {
+	for len(dr.pending) == 0 {
+		if dr.manifest != nil {
+			return 0, io.EOF
+		}
+		if err := dr.readRecord(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, dr.pending)
+	dr.pending = dr.pending[n:]
+	return n, nil
+}
+
+// Manifest returns the verified manifest once the stream has been read to io.EOF.
+func (dr *DecryptReader) Manifest() (StreamManifest, bool) {
+	if dr.manifest == nil {
+		return StreamManifest{}, false
+	}
+	return *dr.manifest, true
+}
+
+func (dr *DecryptReader) readRecord() error {
+	prefix := make([]byte, 5)
+	if _, err := io.ReadFull(dr.r, prefix); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrStreamTruncated
+		}
+		return fmt.Errorf("failed to read record: %w", err)
+	}
+
+	recordType := prefix[0]
+	sealedLen := binary.BigEndian.Uint32(prefix[1:])
+	if sealedLen > uint32(cap(dr.buf)) {
+		return fmt.Errorf("record %v exceeds the chunk size: %v", dr.index, sealedLen)
+	}
+
+	sealed := dr.buf[:sealedLen]
+	if _, err := io.ReadFull(dr.r, sealed); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return ErrStreamTruncated
+		}
+		return fmt.Errorf("failed to read record %v: %w", dr.index, err)
+	}
+
+	plaintext, err := dr.aead.Open(sealed[:0], streamNonce(dr.index), sealed, streamAdditionalData(dr.headerHash, recordType, dr.index))
+	if err != nil {
+		return fmt.Errorf("failed to authenticate record %v: %w", dr.index, err)
+	}
+	dr.index++
+
+	switch recordType {
+	case recordTypeChunk:
+		dr.digest.Write(plaintext)
+		dr.size += uint64(len(plaintext))
+		dr.pending = plaintext
+		return nil
+	case recordTypeManifest:
+		return dr.verifyManifest(plaintext)
+	default:
+		return fmt.Errorf("unknown record type: %v", recordType)
+	}
+}
+
+func (dr *DecryptReader) verifyManifest(bz []byte) error {
+	manifest, err := unmarshalStreamManifest(bz)
+	if err != nil {
+		return err
+	}
+
+	// the manifest itself is the last record, so it is not included in the chunk count
+	if manifest.ChunkCount != dr.index-1 {
+		return fmt.Errorf("chunk count mismatch: manifest:%v, read:%v", manifest.ChunkCount, dr.index-1)
+	}
+	if manifest.Size != dr.size {
+		return fmt.Errorf("size mismatch: manifest:%v, read:%v", manifest.Size, dr.size)
+	}
+	if !bytes.Equal(manifest.Digest[:], dr.digest.Sum(nil)) {
+		return fmt.Errorf("digest mismatch")
+	}
+
+	trailing := make([]byte, 1)
+	if n, _ := dr.r.Read(trailing); n > 0 {
+		return fmt.Errorf("unexpected data after manifest")
+	}
+
+	dr.manifest = &manifest
+	return nil
+}
+
+// EncryptStream encrypts everything from src into dst in the stream format.
+func EncryptStream(dst io.Writer, src io.Reader, pubKey *btcec.PublicKey) (StreamManifest, error) {
+	ew, err := NewEncryptWriter(dst, pubKey)
+	if err != nil {
+		return StreamManifest{}, err
+	}
+	if _, err := io.Copy(ew, src); err != nil {
+		return StreamManifest{}, fmt.Errorf("failed to encrypt stream: %w", err)
+	}
+	if err := ew.Close(); err != nil {
+		return StreamManifest{}, err
+	}
+
+	manifest, _ := ew.Manifest()
+	return manifest, nil
+}
+
+// DecryptStream decrypts the stream from src into dst.
+// Note that dst may have received a part of the plaintext even if an error is returned.
+func DecryptStream(dst io.Writer, src io.Reader, privKey *btcec.PrivateKey) (StreamManifest, error) {
+	dr, err := NewDecryptReader(src, privKey)
+	if err != nil {
+		return StreamManifest{}, err
+	}
+	if _, err := io.Copy(dst, dr); err != nil {
+		return StreamManifest{}, fmt.Errorf("failed to decrypt stream: %w", err)
+	}
+
+	manifest, _ := dr.Manifest()
+	return manifest, nil
+}
+
+// ReEncryptStream decrypts the stream from src using privKey and encrypts it again for pubKey into dst,
+// holding no more than a chunk of plaintext in memory at a time.
+func ReEncryptStream(dst io.Writer, src io.Reader, privKey *btcec.PrivateKey, pubKey *btcec.PublicKey) (StreamManifest, error) {
+	dr, err := NewDecryptReader(src, privKey)
+	if err != nil {
+		return StreamManifest{}, err
+	}
+	return EncryptStream(dst, dr, pubKey)
+}
+
+func marshalStreamHeader(chunkSize uint32, wrappedKey []byte) []byte {
+	header := make([]byte, len(streamMagic)+1+4+2, len(streamMagic)+1+4+2+len(wrappedKey))
+	copy(header, streamMagic)
+	header[len(streamMagic)] = streamVersion
+	binary.BigEndian.PutUint32(header[len(streamMagic)+1:], chunkSize)
+	binary.BigEndian.PutUint16(header[len(streamMagic)+5:], uint16(len(wrappedKey)))
+	return append(header, wrappedKey...)
+}
+
+func readStreamHeader(r io.Reader) (header []byte, chunkSize uint32, wrappedKey []byte, err error) {
+	fixed := make([]byte, len(streamMagic)+1+4+2)
+	if _, err := io.ReadFull(r, fixed); err != nil {
+		return nil, 0, nil, err
+	}
+
+	if !bytes.Equal(fixed[:len(streamMagic)], streamMagic) {
+		return nil, 0, nil, fmt.Errorf("invalid magic")
+	}
+	if version := fixed[len(streamMagic)]; version != streamVersion {
+		return nil, 0, nil, fmt.Errorf("unsupported version: %v", version)
+	}
+
+	chunkSize = binary.BigEndian.Uint32(fixed[len(streamMagic)+1:])
+	if chunkSize == 0 || chunkSize > MaxChunkSize {
+		return nil, 0, nil, fmt.Errorf("invalid chunk size: %v", chunkSize)
+	}
+
+	wrappedKey = make([]byte, binary.BigEndian.Uint16(fixed[len(streamMagic)+5:]))
+	if _, err := io.ReadFull(r, wrappedKey); err != nil {
+		return nil, 0, nil, err
+	}
+
+	return append(fixed, wrappedKey...), chunkSize, wrappedKey, nil
+}
+
+// streamHeaderHash binds the chunks to the header and the context.
+// The context is length-prefixed, so that a context and a header cannot be split differently into the same hash.
+func streamHeaderHash(context, header []byte) [sha256.Size]byte {
+	var contextLen [4]byte
+	binary.BigEndian.PutUint32(contextLen[:], uint32(len(context)))
+
+	hash := sha256.New()
+	hash.Write(contextLen[:])
+	hash.Write(context)
+	hash.Write(header)
+
+	var sum [sha256.Size]byte
+	copy(sum[:], hash.Sum(nil))
+	return sum
+}
+
+func newStreamAEAD(dataKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dataKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init AES cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to init GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// streamNonce derives a unique nonce for each record. Reusing the counter is safe because every stream has a fresh data key.
+func streamNonce(index uint64) []byte {
+	nonce := make([]byte, 12)
+	binary.BigEndian.PutUint64(nonce[4:], index)
+	return nonce
+}
+
+func streamAdditionalData(headerHash [sha256.Size]byte, recordType byte, index uint64) []byte {
+	ad := make([]byte, sha256.Size+1+8)
+	copy(ad, headerHash[:])
+	ad[sha256.Size] = recordType
+	binary.BigEndian.PutUint64(ad[sha256.Size+1:], index)
+	return ad
+}
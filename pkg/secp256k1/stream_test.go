@@ -0,0 +1,194 @@
+package secp256k1
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+)
+
+const testChunkSize = 16
+
+func encryptTestStream(t *testing.T, plaintext []byte, context []byte) ([]byte, []byte) {
+	t.Helper()
+
+	privKey, err := NewPrivKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	ew, err := NewEncryptWriterWithContext(&buf, privKey.PubKey(), testChunkSize, context)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ew.Write(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	if err := ew.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes(), privKey.Serialize()
+}
+
+func decryptTestStream(stream, privKeyBytes, context []byte) ([]byte, error) {
+	dr, err := NewDecryptReaderWithContext(bytes.NewReader(stream), PrivKeyFromBytes(privKeyBytes), context)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(dr)
+}
+
+// splitStream splits the stream into the header and the records.
+func splitStream(t *testing.T, stream []byte) ([]byte, [][]byte) {
+	t.Helper()
+
+	headerLen := len(streamMagic) + 1 + 4 + 2
+	headerLen += int(binary.BigEndian.Uint16(stream[len(streamMagic)+5:]))
+	header, rest := stream[:headerLen], stream[headerLen:]
+
+	var records [][]byte
+	for len(rest) > 0 {
+		recordLen := 5 + int(binary.BigEndian.Uint32(rest[1:5]))
+		records = append(records, rest[:recordLen])
+		rest = rest[recordLen:]
+	}
+	return header, records
+}
+
+func joinStream(header []byte, records [][]byte) []byte {
+	stream := append([]byte{}, header...)
+	for _, record := range records {
+		stream = append(stream, record...)
+	}
+	return stream
+}
+
+func TestStreamRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, testChunkSize - 1, testChunkSize, testChunkSize*3 + 5} {
+		plaintext := make([]byte, size)
+		if _, err := rand.Read(plaintext); err != nil {
+			t.Fatal(err)
+		}
+
+		stream, privKey := encryptTestStream(t, plaintext, []byte("ctx"))
+		decrypted, err := decryptTestStream(stream, privKey, []byte("ctx"))
+		if err != nil {
+			t.Fatalf("size %v: %v", size, err)
+		}
+		if !bytes.Equal(decrypted, plaintext) {
+			t.Fatalf("size %v: plaintext mismatch", size)
+		}
+	}
+}
+
+func TestStreamRoundTripWithHelpers(t *testing.T) {
+	privKey, err := NewPrivKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := bytes.Repeat([]byte("doracle"), 1000)
+
+	var encrypted, decrypted bytes.Buffer
+	encManifest, err := EncryptStream(&encrypted, bytes.NewReader(plaintext), privKey.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	decManifest, err := DecryptStream(&decrypted, &encrypted, privKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decrypted.Bytes(), plaintext) {
+		t.Fatal("plaintext mismatch")
+	}
+	if encManifest != decManifest || decManifest.Size != uint64(len(plaintext)) {
+		t.Fatalf("manifest mismatch: %+v, %+v", encManifest, decManifest)
+	}
+}
+
+func TestStreamWrongContext(t *testing.T) {
+	stream, privKey := encryptTestStream(t, []byte("some data to sell"), []byte("request-1"))
+	if _, err := decryptTestStream(stream, privKey, []byte("request-2")); err == nil {
+		t.Fatal("decrypted with a wrong context")
+	}
+}
+
+func TestStreamHeaderHashSplit(t *testing.T) {
+	if streamHeaderHash([]byte("ab"), []byte("cd")) == streamHeaderHash([]byte("a"), []byte("bcd")) {
+		t.Fatal("different splits of the context and the header hash the same")
+	}
+}
+
+func TestStreamTruncated(t *testing.T) {
+	stream, privKey := encryptTestStream(t, make([]byte, testChunkSize*3), nil)
+	header, records := splitStream(t, stream)
+
+	// without the manifest
+	truncated := joinStream(header, records[:len(records)-1])
+	if _, err := decryptTestStream(truncated, privKey, nil); !errors.Is(err, ErrStreamTruncated) {
+		t.Fatalf("expected ErrStreamTruncated, got %v", err)
+	}
+
+	// in the middle of a record
+	if _, err := decryptTestStream(stream[:len(stream)-3], privKey, nil); !errors.Is(err, ErrStreamTruncated) {
+		t.Fatalf("expected ErrStreamTruncated, got %v", err)
+	}
+}
+
+func TestStreamDroppedChunk(t *testing.T) {
+	stream, privKey := encryptTestStream(t, make([]byte, testChunkSize*3), nil)
+	header, records := splitStream(t, stream)
+
+	dropped := joinStream(header, append([][]byte{records[0]}, records[2:]...))
+	if _, err := decryptTestStream(dropped, privKey, nil); err == nil {
+		t.Fatal("decrypted a stream with a dropped chunk")
+	}
+}
+
+func TestStreamReordered(t *testing.T) {
+	plaintext := make([]byte, testChunkSize*3)
+	if _, err := rand.Read(plaintext); err != nil {
+		t.Fatal(err)
+	}
+	stream, privKey := encryptTestStream(t, plaintext, nil)
+	header, records := splitStream(t, stream)
+
+	records[0], records[1] = records[1], records[0]
+	if _, err := decryptTestStream(joinStream(header, records), privKey, nil); err == nil {
+		t.Fatal("decrypted a stream with reordered chunks")
+	}
+}
+
+func TestStreamTampered(t *testing.T) {
+	stream, privKey := encryptTestStream(t, make([]byte, testChunkSize*2), nil)
+	header, records := splitStream(t, stream)
+
+	for i := range records {
+		tampered := make([][]byte, len(records))
+		copy(tampered, records)
+		tampered[i] = append([]byte{}, records[i]...)
+		tampered[i][len(tampered[i])-1] ^= 1
+
+		if _, err := decryptTestStream(joinStream(header, tampered), privKey, nil); err == nil {
+			t.Fatalf("decrypted a stream with tampered record %v", i)
+		}
+	}
+
+	// a record type flipped from chunk to manifest
+	tampered := append([][]byte{}, records...)
+	tampered[0] = append([]byte{}, records[0]...)
+	tampered[0][0] = recordTypeManifest
+	if _, err := decryptTestStream(joinStream(header, tampered), privKey, nil); err == nil {
+		t.Fatal("decrypted a stream with a tampered record type")
+	}
+}
+
+func TestStreamTrailingData(t *testing.T) {
+	stream, privKey := encryptTestStream(t, []byte("data"), nil)
+	if _, err := decryptTestStream(append(stream, 0), privKey, nil); err == nil {
+		t.Fatal("decrypted a stream with trailing data")
+	}
+}
@@ -0,0 +1,13 @@
+//go:build eclient
+
+package sgx
+
+import (
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/eclient"
+)
+
+// verifyClientRemoteReport verifies the report outside the SGX. It requires the EGo SDK to build.
+func verifyClientRemoteReport(reportBytes []byte) (attestation.Report, error) {
+	return eclient.VerifyRemoteReport(reportBytes)
+}
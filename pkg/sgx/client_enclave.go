@@ -0,0 +1,12 @@
+//go:build !eclient
+
+package sgx
+
+import (
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/enclave"
+)
+
+func verifyClientRemoteReport(reportBytes []byte) (attestation.Report, error) {
+	return enclave.VerifyRemoteReport(reportBytes)
+}
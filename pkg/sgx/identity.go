@@ -0,0 +1,49 @@
+package sgx
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"github.com/edgelesssys/ego/attestation"
+	"github.com/edgelesssys/ego/enclave"
+)
+
+type Identity struct {
+	SignerID        string `json:"signer_id"`
+	UniqueID        string `json:"unique_id"`
+	ProductID       uint16 `json:"product_id"`
+	SecurityVersion uint   `json:"security_version"`
+	Debug           bool   `json:"debug"`
+}
+
+// SelfIdentity returns the identity of the running enclave.
+// It generates a remote report and verifies it, so it works only in the SGX-FLC environment.
+func SelfIdentity() (Identity, error) {
+	reportBytes, err := enclave.GetRemoteReport(nil)
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to generate report: %w", err)
+	}
+
+	report, err := enclave.VerifyRemoteReport(reportBytes)
+	// the identity is still valid even if the TCB level of the platform is not up-to-date
+	if err != nil && !errors.Is(err, attestation.ErrTCBLevelInvalid) {
+		return Identity{}, fmt.Errorf("failed to verify report: %w", err)
+	}
+
+	return identityFromReport(report), nil
+}
+
+func identityFromReport(report attestation.Report) Identity {
+	identity := Identity{
+		SignerID:        hex.EncodeToString(report.SignerID),
+		UniqueID:        hex.EncodeToString(report.UniqueID),
+		SecurityVersion: report.SecurityVersion,
+		Debug:           report.Debug,
+	}
+	if len(report.ProductID) >= 2 {
+		identity.ProductID = binary.LittleEndian.Uint16(report.ProductID)
+	}
+	return identity
+}
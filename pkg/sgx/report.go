@@ -4,47 +4,88 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/edgelesssys/ego/attestation"
 	"github.com/edgelesssys/ego/enclave"
 )
 
+// Errors returned by VerifyRemoteReport. Their messages are published by voters when a join is rejected,
+// so that the joiner can tell why.
+var (
+	ErrReportVerification = errors.New("failed to verify report")
+	ErrTCBStatus          = errors.New("TCB of the platform is not up to date")
+	ErrReportData         = errors.New("invalid data in the report")
+	ErrSecurityVersion    = errors.New("invalid security version in the report")
+	ErrProductID          = errors.New("invalid product ID in the report")
+	ErrSignerID           = errors.New("invalid signer ID in the report")
+)
+
 // GenerateRemotePeport generates a SGX report containing the specified data for use in remote attestation.
 // This works only in the SGX-FLC environment where the SGX quote provider is installed.
 func GenerateRemotePeport(data []byte) ([]byte, error) {
 	return enclave.GetRemoteReport(data)
 }
 
+// The policy that reports must satisfy. It is compiled into the binary, so that it is covered by the measurement
+// of the enclave, and the host cannot make the enclave trust an enclave built by someone else.
 const (
-	promisedMinSecurityVersion = 1
-	promisedProductID          = 1
-	promisedSignerID           = "5e54e2a96066cf6a20b59e4f0b10cd3f3ecad2dd598c5623a0802821d043dc42"
+	SignerID           = "5e54e2a96066cf6a20b59e4f0b10cd3f3ecad2dd598c5623a0802821d043dc42"
+	ProductID          = 1
+	MinSecurityVersion = 1
 )
 
+var minSecurityVersion uint = MinSecurityVersion
+
+// RaiseMinSecurityVersion tightens the policy by requiring a security version higher than the built-in one,
+// e.g. to stop trusting a vulnerable release. It refuses to loosen the policy.
+// It must be called on startup before any report is verified.
+func RaiseMinSecurityVersion(v uint) error {
+	if v < MinSecurityVersion {
+		return fmt.Errorf("min security version cannot be lowered below %v: %v", MinSecurityVersion, v)
+	}
+	minSecurityVersion = v
+	return nil
+}
+
 // VerifyRemoteReport verifies whether the report not only was properly generated in the SGX environment,
-// but also contains the promised security version, product ID, and signer ID,
+// but also satisfies the policy of the security version, product ID, and signer ID,
 // in order to verify that the report was generated by the promised binary which was not forged.
 func VerifyRemoteReport(reportBytes, expectedData []byte) error {
 	report, err := enclave.VerifyRemoteReport(reportBytes)
-	// if err == attestation.ErrTCBLevelInvalid {
-	// 	fmt.Printf("Warning: TCB level is invalid: %v\n%v\n", report.TCBStatus, tcbstatus.Explain(report.TCBStatus))
-	// 	fmt.Println("We'll ignore this issue in this sample. For an app that should run in production, you must decide which of the different TCBStatus values are acceptable for you to continue.")
-	// }
+	return checkReport(report, err, expectedData)
+}
+
+// VerifyRemoteReportAsClient verifies the report against the same policy as VerifyRemoteReport, for clients verifying the oracle.
+// If built with the eclient tag, the report is verified by the EGo client library, so that it can be called outside the SGX.
+// Otherwise, it must be called in the SGX.
+func VerifyRemoteReportAsClient(reportBytes, expectedData []byte) error {
+	report, err := verifyClientRemoteReport(reportBytes)
+	return checkReport(report, err, expectedData)
+}
+
+// checkReport checks the result of verifying a report against the policy.
+func checkReport(report attestation.Report, err error, expectedData []byte) error {
+	if errors.Is(err, attestation.ErrTCBLevelInvalid) {
+		return fmt.Errorf("%w: %v", ErrTCBStatus, report.TCBStatus)
+	}
 	if err != nil {
-		return fmt.Errorf("failed to verify report: %w", err)
+		return fmt.Errorf("%w: %v", ErrReportVerification, err)
 	}
 
-	if !bytes.Equal(report.Data[:len(expectedData)], expectedData) {
-		return fmt.Errorf("invalid data in the report")
+	if len(report.Data) < len(expectedData) || !bytes.Equal(report.Data[:len(expectedData)], expectedData) {
+		return ErrReportData
 	}
-	if report.SecurityVersion < promisedMinSecurityVersion {
-		return fmt.Errorf("invalid security version in the report")
+	if report.SecurityVersion < minSecurityVersion {
+		return fmt.Errorf("%w: %v < %v", ErrSecurityVersion, report.SecurityVersion, minSecurityVersion)
 	}
-	if binary.LittleEndian.Uint16(report.ProductID) != promisedProductID {
-		return fmt.Errorf("invalid product ID in the report")
+	if productID := binary.LittleEndian.Uint16(report.ProductID); productID != ProductID {
+		return fmt.Errorf("%w: %v != %v", ErrProductID, productID, ProductID)
 	}
-	if hex.EncodeToString(report.SignerID) != promisedSignerID {
-		return fmt.Errorf("invalid signer ID in the report")
+	if signerID := hex.EncodeToString(report.SignerID); !strings.EqualFold(signerID, SignerID) {
+		return fmt.Errorf("%w: %v != %v", ErrSignerID, signerID, SignerID)
 	}
 	//TODO: check unique ID
 
@@ -8,10 +8,11 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// SealToFile seals data with the key bound to the product of the signer, so that any version of the oracle can unseal it.
 func SealToFile(data []byte, filePath string) error {
 	sealed, err := ecrypto.SealWithProductKey(data, nil)
 	if err != nil {
-		return fmt.Errorf("failed to seal oracle key: %w", err)
+		return fmt.Errorf("failed to seal: %w", err)
 	}
 
 	if err := ioutil.WriteFile(filePath, sealed, 0644); err != nil {
@@ -30,8 +31,17 @@ func UnsealFromFile(filePath string) ([]byte, error) {
 
 	key, err := ecrypto.Unseal(sealed, nil)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unseal oracle key: %w", err)
+		return nil, fmt.Errorf("failed to unseal: %w", err)
 	}
 
 	return key, nil
 }
+
+// Seal seals data with the key bound to this enclave binary, for data which is only meaningful to this oracle node.
+func Seal(data []byte) ([]byte, error) {
+	return ecrypto.SealWithUniqueKey(data, nil)
+}
+
+func Unseal(sealed []byte) ([]byte, error) {
+	return ecrypto.Unseal(sealed, nil)
+}
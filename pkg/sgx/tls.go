@@ -0,0 +1,103 @@
+package sgx
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/edgelesssys/ego/enclave"
+)
+
+const attestedCertValidity = 365 * 24 * time.Hour
+
+// oidReport is the X.509 extension in which EGo (Open Enclave) embeds a remote report.
+var oidReport = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 105, 1}
+
+// CreateAttestedServerTLSConfig creates a TLS config with a self-signed certificate
+// whose public key hash is bound into a SGX remote report embedded in the certificate.
+// Clients can verify the server using CreateAttestedClientTLSConfig without any CA.
+func CreateAttestedServerTLSConfig() (*tls.Config, error) {
+	priv, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate TLS key: %w", err)
+	}
+
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+
+	now := time.Now()
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: "doracle-poc"},
+		NotBefore:    now,
+		NotAfter:     now.Add(attestedCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+
+	cert, err := enclave.CreateAttestationCertificate(template, template, &priv.PublicKey, priv)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create attested certificate: %w", err)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{
+			{
+				Certificate: [][]byte{cert},
+				PrivateKey:  priv,
+			},
+		},
+		MinVersion: tls.VersionTLS12,
+	}, nil
+}
+
+// CreateAttestedClientTLSConfig creates a TLS config which accepts only the server certificate
+// created by CreateAttestedServerTLSConfig, whose report satisfies the same policy as VerifyRemoteReport.
+// The report is verified by VerifyRemoteReportAsClient, so this must be used in the SGX unless built with the eclient tag.
+func CreateAttestedClientTLSConfig() *tls.Config {
+	return &tls.Config{
+		// The certificate is self-signed, so it is verified by VerifyPeerCertificate instead.
+		InsecureSkipVerify:    true,
+		VerifyPeerCertificate: verifyAttestedCertificate,
+		MinVersion:            tls.VersionTLS12,
+	}
+}
+
+func verifyAttestedCertificate(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+	if len(rawCerts) == 0 {
+		return fmt.Errorf("no certificate")
+	}
+	cert, err := x509.ParseCertificate(rawCerts[0])
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(cert)
+	if _, err := cert.Verify(x509.VerifyOptions{Roots: roots}); err != nil {
+		return fmt.Errorf("failed to verify self-signed certificate: %w", err)
+	}
+
+	pubKeyBytes, err := x509.MarshalPKIXPublicKey(cert.PublicKey)
+	if err != nil {
+		return fmt.Errorf("failed to marshal public key: %w", err)
+	}
+	pubKeyHash := sha256.Sum256(pubKeyBytes)
+
+	for _, ext := range cert.Extensions {
+		if ext.Id.Equal(oidReport) {
+			return VerifyRemoteReportAsClient(ext.Value, pubKeyHash[:])
+		}
+	}
+	return fmt.Errorf("no report in certificate")
+}
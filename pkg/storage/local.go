@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// LocalStorage is a content-addressed storage on a local (or mounted) directory.
+// The reference of an object is the hex-encoded SHA-256 of its content.
+type LocalStorage struct {
+	dir string
+}
+
+func NewLocalStorage(dir string) (*LocalStorage, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("storage directory not specified")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %v: %w", dir, err)
+	}
+
+	return &LocalStorage{dir: dir}, nil
+}
+
+func (s *LocalStorage) Put(_ context.Context, r io.Reader) (string, error) {
+	tmp, err := ioutil.TempFile(s.dir, ".put-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(tmp, hash), r); err != nil {
+		return "", fmt.Errorf("failed to write %v: %w", tmp.Name(), err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", fmt.Errorf("failed to close %v: %w", tmp.Name(), err)
+	}
+
+	ref := hex.EncodeToString(hash.Sum(nil))
+	if err := os.Rename(tmp.Name(), s.path(ref)); err != nil {
+		return "", fmt.Errorf("failed to store %v: %w", ref, err)
+	}
+
+	return ref, nil
+}
+
+func (s *LocalStorage) Get(_ context.Context, ref string) (io.ReadCloser, error) {
+	if _, err := hex.DecodeString(ref); err != nil || len(ref) != sha256.Size*2 {
+		return nil, fmt.Errorf("invalid reference: %v", ref)
+	}
+
+	f, err := os.Open(s.path(ref))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %v: %w", ref, err)
+	}
+	return f, nil
+}
+
+func (s *LocalStorage) path(ref string) string {
+	return filepath.Join(s.dir, ref)
+}
@@ -0,0 +1,30 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+)
+
+// Storage stores ciphertexts that are too large to be put on chain.
+// A reference returned by Put is what is recorded on chain, and it can be passed to Get by anyone.
+type Storage interface {
+	Put(ctx context.Context, r io.Reader) (ref string, err error)
+	Get(ctx context.Context, ref string) (io.ReadCloser, error)
+}
+
+// New returns a Storage for the URI. Only the file scheme (or a plain directory path) is supported for now.
+func New(uri string) (Storage, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storage URI %v: %w", uri, err)
+	}
+
+	switch u.Scheme {
+	case "", "file":
+		return NewLocalStorage(u.Path)
+	default:
+		return nil, fmt.Errorf("unsupported storage scheme: %v", u.Scheme)
+	}
+}
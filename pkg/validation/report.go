@@ -0,0 +1,250 @@
+package validation
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/oraclesig"
+)
+
+const ReportVersion = 2
+
+// Report is a statistics report of the data, computed in the enclave.
+// Its schema is fixed, and each field is reported only if the rules allow it.
+// RequestID and RulesHash bind the report to the request and the rules (see Rules.Hash),
+// so that a report cannot be presented for another request or as if computed with other rules.
+type Report struct {
+	Version        uint32        `json:"version"`
+	RequestID      string        `json:"request_id"`
+	RulesHash      string        `json:"rules_hash"`
+	ContentHash    string        `json:"content_hash"`
+	Valid          bool          `json:"valid"`
+	RowCount       *uint64       `json:"row_count,omitempty"`
+	SchemaCoverage *float64      `json:"schema_coverage,omitempty"`
+	Columns        []ColumnStats `json:"columns,omitempty"`
+}
+
+type ColumnStats struct {
+	Name      string     `json:"name"`
+	Present   bool       `json:"present"`
+	NullRate  *float64   `json:"null_rate,omitempty"`
+	DateRange *DateRange `json:"date_range,omitempty"`
+}
+
+// DateRange is truncated to the granularity of the rules.
+type DateRange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ComputeReport reads the CSV data with a header row from r and computes a report according to the rules.
+// Rows are processed one by one, so that the data doesn't have to be held in memory.
+func ComputeReport(r io.Reader, rules Rules) (Report, error) {
+	if err := rules.Validate(); err != nil {
+		return Report{}, fmt.Errorf("invalid rules: %w", err)
+	}
+
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to read header: %w", err)
+	}
+	columnIndexes := make(map[string]int, len(header))
+	for i, name := range header {
+		columnIndexes[strings.TrimSpace(name)] = i
+	}
+
+	accs := make([]*columnAccumulator, len(rules.Columns))
+	for i, name := range rules.Columns {
+		idx, present := columnIndexes[name]
+		accs[i] = &columnAccumulator{index: idx, present: present, isDate: contains(rules.DateColumns, name)}
+	}
+
+	var rowCount uint64
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Report{}, fmt.Errorf("failed to read row %v: %w", rowCount+1, err)
+		}
+		rowCount++
+
+		for _, acc := range accs {
+			acc.add(record, rules.DateLayout)
+		}
+	}
+
+	report := buildReport(rules, rowCount, accs)
+	if report.RulesHash, err = rules.Hash(); err != nil {
+		return Report{}, err
+	}
+	return report, nil
+}
+
+func buildReport(rules Rules, rowCount uint64, accs []*columnAccumulator) Report {
+	presentCount := 0
+	invalidDates := false
+	for _, acc := range accs {
+		if acc.present {
+			presentCount++
+		}
+		if acc.invalidDates > 0 {
+			invalidDates = true
+		}
+	}
+
+	report := Report{
+		Version: ReportVersion,
+		Valid:   presentCount == len(accs) && rowCount >= rules.MinRowCount && !invalidDates,
+	}
+
+	if rules.Allows(FieldRowCount) {
+		report.RowCount = &rowCount
+	}
+	if rules.Allows(FieldSchemaCoverage) {
+		coverage := roundDown(float64(presentCount)/float64(len(accs)), rules.RatePrecision)
+		report.SchemaCoverage = &coverage
+	}
+
+	// per-column statistics of a small data set can identify individual rows
+	if rowCount < rules.MinRowCount {
+		return report
+	}
+
+	for i, acc := range accs {
+		stats := ColumnStats{
+			Name:    rules.Columns[i],
+			Present: acc.present,
+		}
+		if acc.present && rules.Allows(FieldNullRate) {
+			nullRate := roundDown(float64(acc.nulls)/float64(rowCount), rules.RatePrecision)
+			stats.NullRate = &nullRate
+		}
+		if acc.present && acc.isDate && acc.dates > 0 && rules.Allows(FieldDateRange) {
+			stats.DateRange = &DateRange{
+				From: truncateDate(acc.minDate, rules.DateGranularity),
+				To:   truncateDate(acc.maxDate, rules.DateGranularity),
+			}
+		}
+		report.Columns = append(report.Columns, stats)
+	}
+
+	return report
+}
+
+// SignedReport carries the report in the exact bytes that were signed,
+// so that verifiers don't need to re-encode the report.
+type SignedReport struct {
+	ChainID   string          `json:"chain_id"`
+	Report    json.RawMessage `json:"report"`
+	Signature []byte          `json:"signature"`
+}
+
+// Sign signs the report with the oracle key, so that anyone can verify that the report was generated in the enclave.
+func (r Report) Sign(oraclePrivKey *btcec.PrivateKey, chainID string) (SignedReport, error) {
+	bz, err := json.Marshal(r)
+	if err != nil {
+		return SignedReport{}, fmt.Errorf("failed to marshal report: %w", err)
+	}
+
+	sig, err := oraclesig.Sign(oraclePrivKey, oraclesig.DomainValidationReport, chainID, bz)
+	if err != nil {
+		return SignedReport{}, fmt.Errorf("failed to sign report: %w", err)
+	}
+
+	return SignedReport{
+		ChainID:   chainID,
+		Report:    bz,
+		Signature: sig,
+	}, nil
+}
+
+// Verify verifies the signature using the oracle public key registered on chain, and returns the report.
+func (s SignedReport) Verify(oraclePubKey *btcec.PublicKey, chainID string) (Report, error) {
+	if s.ChainID != chainID {
+		return Report{}, fmt.Errorf("report was signed for another chain: %v", s.ChainID)
+	}
+	if err := oraclesig.Verify(oraclePubKey, oraclesig.DomainValidationReport, chainID, s.Report, s.Signature); err != nil {
+		return Report{}, err
+	}
+
+	var report Report
+	if err := json.Unmarshal(s.Report, &report); err != nil {
+		return Report{}, fmt.Errorf("failed to unmarshal report: %w", err)
+	}
+	return report, nil
+}
+
+type columnAccumulator struct {
+	index   int
+	present bool
+	isDate  bool
+
+	nulls        uint64
+	dates        uint64
+	invalidDates uint64
+	minDate      time.Time
+	maxDate      time.Time
+}
+
+func (a *columnAccumulator) add(record []string, dateLayout string) {
+	if !a.present {
+		return
+	}
+	if a.index >= len(record) || strings.TrimSpace(record[a.index]) == "" {
+		a.nulls++
+		return
+	}
+	if !a.isDate {
+		return
+	}
+
+	date, err := time.Parse(dateLayout, strings.TrimSpace(record[a.index]))
+	if err != nil {
+		a.invalidDates++
+		return
+	}
+	if a.dates == 0 || date.Before(a.minDate) {
+		a.minDate = date
+	}
+	if a.dates == 0 || date.After(a.maxDate) {
+		a.maxDate = date
+	}
+	a.dates++
+}
+
+func truncateDate(t time.Time, granularity Granularity) string {
+	switch granularity {
+	case GranularityYear:
+		return t.Format("2006")
+	case GranularityMonth:
+		return t.Format("2006-01")
+	default:
+		return t.Format("2006-01-02")
+	}
+}
+
+func roundDown(v float64, precision int) float64 {
+	p := math.Pow10(precision)
+	return math.Floor(v*p) / p
+}
+
+func contains(list []string, s string) bool {
+	for _, e := range list {
+		if e == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,144 @@
+package validation
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// Field is a statistic which can be included in a report.
+// Only these fields can ever be reported, so that no raw value of the data can be revealed.
+type Field string
+
+const (
+	FieldRowCount       Field = "row_count"
+	FieldSchemaCoverage Field = "schema_coverage"
+	FieldNullRate       Field = "null_rate"
+	FieldDateRange      Field = "date_range"
+)
+
+type Granularity string
+
+const (
+	GranularityDay   Granularity = "day"
+	GranularityMonth Granularity = "month"
+	GranularityYear  Granularity = "year"
+)
+
+const (
+	// MinRowCountFloor is the smallest MinRowCount allowed, below which statistics can identify individual rows.
+	MinRowCountFloor = 5
+	// MaxRatePrecision is the max number of decimal places of rates such as null rates.
+	MaxRatePrecision = 3
+)
+
+type Rules struct {
+	// Columns is the schema expected by the buyer. Statistics are reported only for these columns.
+	Columns []string `json:"columns"`
+	// DateColumns are the columns whose date range is reported. They must be a subset of Columns.
+	DateColumns []string `json:"date_columns"`
+	// DateLayout is the Go time layout of the date columns.
+	DateLayout string `json:"date_layout"`
+	// DateGranularity is the granularity that date ranges are truncated to.
+	DateGranularity Granularity `json:"date_granularity"`
+	// MinRowCount is the number of rows below which the data is rejected and no per-column statistics are reported.
+	MinRowCount uint64 `json:"min_row_count"`
+	// RatePrecision is the number of decimal places that null rates and the schema coverage are rounded down to.
+	RatePrecision int `json:"rate_precision"`
+	// Fields are the statistics allowed to be reported.
+	Fields []Field `json:"fields"`
+}
+
+func DefaultRules(columns []string) Rules {
+	return Rules{
+		Columns:         columns,
+		DateColumns:     nil,
+		DateLayout:      "2006-01-02",
+		DateGranularity: GranularityMonth,
+		MinRowCount:     100,
+		RatePrecision:   2,
+		Fields:          []Field{FieldRowCount, FieldSchemaCoverage, FieldNullRate, FieldDateRange},
+	}
+}
+
+func LoadRules(path string) (Rules, error) {
+	bz, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Rules{}, fmt.Errorf("failed to read %v: %w", path, err)
+	}
+
+	var rules Rules
+	if err := json.Unmarshal(bz, &rules); err != nil {
+		return Rules{}, fmt.Errorf("failed to parse %v: %w", path, err)
+	}
+	if err := rules.Validate(); err != nil {
+		return Rules{}, fmt.Errorf("invalid rules in %v: %w", path, err)
+	}
+
+	return rules, nil
+}
+
+// Validate rejects rules which could make a report leak raw values.
+func (r Rules) Validate() error {
+	if len(r.Columns) == 0 {
+		return fmt.Errorf("no column specified")
+	}
+	if r.MinRowCount < MinRowCountFloor {
+		return fmt.Errorf("min_row_count must be >= %v", MinRowCountFloor)
+	}
+	if r.RatePrecision < 0 || r.RatePrecision > MaxRatePrecision {
+		return fmt.Errorf("rate_precision must be in [0, %v]", MaxRatePrecision)
+	}
+
+	for _, field := range r.Fields {
+		switch field {
+		case FieldRowCount, FieldSchemaCoverage, FieldNullRate, FieldDateRange:
+		default:
+			return fmt.Errorf("field not allowed: %v", field)
+		}
+	}
+
+	if r.Allows(FieldDateRange) {
+		switch r.DateGranularity {
+		case GranularityDay, GranularityMonth, GranularityYear:
+		default:
+			return fmt.Errorf("invalid date_granularity: %v", r.DateGranularity)
+		}
+		if r.DateLayout == "" {
+			return fmt.Errorf("date_layout must be specified")
+		}
+	}
+
+	columns := make(map[string]bool, len(r.Columns))
+	for _, column := range r.Columns {
+		columns[column] = true
+	}
+	for _, column := range r.DateColumns {
+		if !columns[column] {
+			return fmt.Errorf("date column %v is not in columns", column)
+		}
+	}
+
+	return nil
+}
+
+// Hash returns the hex-encoded SHA-256 of the rules in JSON, which binds a report to the rules it was computed with.
+func (r Rules) Hash() (string, error) {
+	bz, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rules: %w", err)
+	}
+	hash := sha256.Sum256(bz)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func (r Rules) Allows(field Field) bool {
+	for _, f := range r.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package validation
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/youngjoon-lee/doracle-poc/pkg/envelope"
+)
+
+// Validate decrypts the data encrypted by the seller using the oracle key, computes a report for the request, and signs it.
+// The plaintext never leaves the enclave, and only a chunk of it is held in memory at a time.
+func Validate(encrypted io.Reader, oraclePrivKey *btcec.PrivateKey, chainID, requestID string, rules Rules) (SignedReport, error) {
+	dr, _, err := envelope.NewStreamReader(encrypted, oraclePrivKey)
+	if err != nil {
+		return SignedReport{}, fmt.Errorf("failed to init decryption: %w", err)
+	}
+
+	report, err := ComputeReport(dr, rules)
+	if err != nil {
+		return SignedReport{}, fmt.Errorf("failed to compute report: %w", err)
+	}
+
+	// the manifest is verified only after the whole stream is read
+	if _, err := io.Copy(io.Discard, dr); err != nil {
+		return SignedReport{}, fmt.Errorf("failed to decrypt: %w", err)
+	}
+	manifest, ok := dr.Manifest()
+	if !ok {
+		return SignedReport{}, fmt.Errorf("stream manifest not verified")
+	}
+	report.RequestID = requestID
+	report.ContentHash = hex.EncodeToString(manifest.Digest[:])
+
+	return report.Sign(oraclePrivKey, chainID)
+}